@@ -74,6 +74,12 @@ var commands = []*discordgo.ApplicationCommand{
 			},
 		},
 	},
+	{
+		Name:         "recommend",
+		Description:  "Get game suggestions based on past flavors of the week and ratings",
+		Type:         discordgo.ChatApplicationCommand,
+		DMPermission: Ptr(false),
+	},
 	{
 		Name:         "nominations",
 		Description:  "Manage nominations for the next poll",
@@ -167,6 +173,195 @@ var commands = []*discordgo.ApplicationCommand{
 				Required:     false,
 				Autocomplete: true,
 			},
+			{
+				Name:        "tag",
+				Description: "Only list activities with this tag",
+				Type:        discordgo.ApplicationCommandOptionString,
+				Required:    false,
+			},
+			{
+				Name:        "sort",
+				Description: "Order of the list",
+				Type:        discordgo.ApplicationCommandOptionString,
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "Name",
+						Value: "name",
+					},
+					{
+						Name:  "Newest",
+						Value: "newest",
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:                     "alias",
+		Description:              "Manage the nicknames of a game/activity",
+		Type:                     discordgo.ChatApplicationCommand,
+		DefaultMemberPermissions: Ptr(int64(discordgo.PermissionAdministrator)),
+		DMPermission:             Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "add",
+				Description: "Add aliases to a game/activity",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:         "name",
+						Description:  "Name of the game/activity",
+						Type:         discordgo.ApplicationCommandOptionString,
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Name:        "aliases",
+						Description: "Comma separated aliases e.g. \"DRG, rock and stone\"",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Name:        "remove",
+				Description: "Remove aliases from a game/activity",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:         "name",
+						Description:  "Name of the game/activity",
+						Type:         discordgo.ApplicationCommandOptionString,
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Name:        "aliases",
+						Description: "Comma separated aliases e.g. \"DRG, rock and stone\"",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:         "own",
+		Description:  "Track which games in the pool you own",
+		Type:         discordgo.ChatApplicationCommand,
+		DMPermission: Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "add",
+				Description: "Mark a game as owned by you",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:         "name",
+						Description:  "Name of the game",
+						Type:         discordgo.ApplicationCommandOptionString,
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			{
+				Name:        "remove",
+				Description: "Unmark a game as owned by you",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:         "name",
+						Description:  "Name of the game",
+						Type:         discordgo.ApplicationCommandOptionString,
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			{
+				Name:        "list",
+				Description: "List the games you own",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+			},
+		},
+	},
+	{
+		Name:         "players",
+		Description:  "Set how many players a game/activity needs",
+		Type:         discordgo.ChatApplicationCommand,
+		DMPermission: Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:         "name",
+				Description:  "Name of the game/activity",
+				Type:         discordgo.ApplicationCommandOptionString,
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Name:        "min",
+				Description: "Minimum number of players. 0 if unknown",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Required:    true,
+				MinValue:    Ptr(0.0),
+			},
+			{
+				Name:        "max",
+				Description: "Maximum number of players. Leave empty if there is no limit",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Required:    false,
+				MinValue:    Ptr(1.0),
+			},
+		},
+	},
+	{
+		Name:         "tag",
+		Description:  "Manage the tags of a game/activity",
+		Type:         discordgo.ChatApplicationCommand,
+		DMPermission: Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "add",
+				Description: "Add tags to a game/activity",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:         "name",
+						Description:  "Name of the game/activity",
+						Type:         discordgo.ApplicationCommandOptionString,
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Name:        "tags",
+						Description: "Comma separated tags e.g. \"co-op, short\"",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Name:        "remove",
+				Description: "Remove tags from a game/activity",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:         "name",
+						Description:  "Name of the game/activity",
+						Type:         discordgo.ApplicationCommandOptionString,
+						Required:     true,
+						Autocomplete: true,
+					},
+					{
+						Name:        "tags",
+						Description: "Comma separated tags e.g. \"co-op, short\"",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+					},
+				},
+			},
 		},
 	},
 	{
@@ -175,6 +370,137 @@ var commands = []*discordgo.ApplicationCommand{
 		Type:         discordgo.ChatApplicationCommand,
 		DMPermission: Ptr(false),
 	},
+	{
+		Name:                     "edit",
+		Description:              "Edit a game/activity. Without options a form for its details is opened",
+		Type:                     discordgo.ChatApplicationCommand,
+		DefaultMemberPermissions: Ptr(int64(discordgo.PermissionAdministrator)),
+		DMPermission:             Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:         "name",
+				Description:  "Name of the game/activity",
+				Type:         discordgo.ApplicationCommandOptionString,
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Name:        "type",
+				Description: "Change whether it is a game or an activity",
+				Type:        discordgo.ApplicationCommandOptionString,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "Game",
+						Value: "game",
+					},
+					{
+						Name:  "Activity",
+						Value: "activity",
+					},
+				},
+			},
+			{
+				Name:         "game",
+				Description:  "Link to a different game",
+				Type:         discordgo.ApplicationCommandOptionString,
+				Autocomplete: true,
+			},
+			{
+				Name:        "emoji",
+				Description: "Emoji shown in polls. \"none\" uses the default emoji",
+				Type:        discordgo.ApplicationCommandOptionString,
+			},
+		},
+	},
+	{
+		Name:                     "rename",
+		Description:              "Rename a game/activity keeping its nominations and history",
+		Type:                     discordgo.ChatApplicationCommand,
+		DefaultMemberPermissions: Ptr(int64(discordgo.PermissionAdministrator)),
+		DMPermission:             Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:         "name",
+				Description:  "Current name of the game/activity",
+				Type:         discordgo.ApplicationCommandOptionString,
+				Required:     true,
+				Autocomplete: true,
+			},
+			{
+				Name:        "new-name",
+				Description: "New name",
+				Type:        discordgo.ApplicationCommandOptionString,
+				Required:    true,
+				MaxLength:   55,
+			},
+		},
+	},
+	{
+		Name:         "activity-info",
+		Description:  "Show details and ratings of a game/activity",
+		Type:         discordgo.ChatApplicationCommand,
+		DMPermission: Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:         "name",
+				Description:  "Name of the game/activity",
+				Type:         discordgo.ApplicationCommandOptionString,
+				Required:     true,
+				Autocomplete: true,
+			},
+		},
+	},
+	{
+		Name:         "roster",
+		Description:  "See who is playing the flavor of the week",
+		Type:         discordgo.ChatApplicationCommand,
+		DMPermission: Ptr(false),
+	},
+	{
+		Name:         "teams",
+		Description:  "Split the players of the flavor of the week into random teams",
+		Type:         discordgo.ChatApplicationCommand,
+		DMPermission: Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "count",
+				Description: "Number of teams",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Required:    true,
+				MinValue:    Ptr(2.0),
+				MaxValue:    10,
+			},
+			{
+				Name:         "channel",
+				Description:  "Team up the members of this voice channel instead of the players going",
+				Type:         discordgo.ApplicationCommandOptionChannel,
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildVoice},
+			},
+			{
+				Name:        "going-only",
+				Description: "Only team up the members of the voice channel who are going",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+			},
+			{
+				Name:        "avoid-repeats",
+				Description: "Avoid pairing players that were on the same team last time",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+			},
+		},
+	},
+	{
+		Name:         "my-votes",
+		Description:  "See how often your picks won recent polls",
+		Type:         discordgo.ChatApplicationCommand,
+		DMPermission: Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "tracking",
+				Description: "Record your votes. Turning this off deletes your recorded votes",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+			},
+		},
+	},
 	{
 		Name:         "help",
 		Description:  "Displays info on how to use the bot",
@@ -197,6 +523,112 @@ var commands = []*discordgo.ApplicationCommand{
 			},
 		},
 	},
+	{
+		Name:                     "session",
+		Description:              "Manage the weekly session scheduled for the flavor of the week",
+		Type:                     discordgo.ChatApplicationCommand,
+		DefaultMemberPermissions: Ptr(int64(discordgo.PermissionAdministrator)),
+		DMPermission:             Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "set",
+				Description: "Set the default session slot in UTC",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "day",
+						Description: "Day of the session",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Sunday",
+								Value: "Sunday",
+							},
+							{
+								Name:  "Monday",
+								Value: "Monday",
+							},
+							{
+								Name:  "Tuesday",
+								Value: "Tuesday",
+							},
+							{
+								Name:  "Wednesday",
+								Value: "Wednesday",
+							},
+							{
+								Name:  "Thursday",
+								Value: "Thursday",
+							},
+							{
+								Name:  "Friday",
+								Value: "Friday",
+							},
+							{
+								Name:  "Saturday",
+								Value: "Saturday",
+							},
+						},
+					},
+					{
+						Name:        "hour",
+						Description: "Hour the session starts",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Required:    true,
+						MinValue:    Ptr(0.0),
+						MaxValue:    23,
+					},
+					{
+						Name:         "channel",
+						Description:  "Voice channel of the session",
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Required:     true,
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildVoice},
+					},
+					{
+						Name:        "minute",
+						Description: "Minute the session starts",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Required:    false,
+						MinValue:    Ptr(0.0),
+						MaxValue:    59,
+					},
+					{
+						Name:        "duration",
+						Description: "Length of the session in hours. Defaults to 3",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Required:    false,
+						MinValue:    Ptr(1.0),
+						MaxValue:    24,
+					},
+				},
+			},
+			{
+				Name:        "time-poll",
+				Description: "Poll for the session time after each flavor of the week is picked",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "slots",
+						Description: "Candidate times in UTC e.g. \"Saturday 20:00, Sunday 18:30\". Leave empty to disable",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    false,
+					},
+				},
+			},
+			{
+				Name:        "cancel",
+				Description: "Cancel the next session",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+			},
+			{
+				Name:        "clear",
+				Description: "Stop scheduling sessions",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+			},
+		},
+	},
 	{
 		Name:                     "schedule-poll",
 		Description:              "Set the schedule for polls in UTC",
@@ -250,6 +682,225 @@ var commands = []*discordgo.ApplicationCommand{
 			},
 		},
 	},
+	{
+		Name:                     "poll",
+		Description:              "Manage the next poll",
+		Type:                     discordgo.ChatApplicationCommand,
+		DefaultMemberPermissions: Ptr(int64(discordgo.PermissionAdministrator)),
+		DMPermission:             Ptr(false),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "preview",
+				Description: "Preview the entries of the next poll before publishing it",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+			},
+			{
+				Name:        "ownership",
+				Description: "Prefer or require games owned by enough members",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "mode",
+						Description: "How game ownership affects polls",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Off",
+								Value: "off",
+							},
+							{
+								Name:  "Prefer owned games",
+								Value: "prefer",
+							},
+							{
+								Name:  "Require owned games",
+								Value: "require",
+							},
+						},
+					},
+					{
+						Name:        "min-owners",
+						Description: "Number of members that must own a game. Defaults to 1",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Required:    false,
+						MinValue:    Ptr(1.0),
+					},
+				},
+			},
+			{
+				Name:        "players",
+				Description: "Only include activities that fit the number of players expected in the next poll",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "count",
+						Description: "Expected number of players. Leave empty for any number",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Required:    false,
+						MinValue:    Ptr(1.0),
+					},
+				},
+			},
+			{
+				Name:        "themed",
+				Description: "Start a poll drawn only from activities with a tag",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "tag",
+						Description: "Tag the poll entries must have",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Name:        "composition",
+				Description: "View or set how poll entries are chosen",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "slots",
+						Description: "Providers and weights e.g. \"pinned:1 nominations:4 random:2\". Use \"default\" to reset",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    false,
+					},
+				},
+			},
+			{
+				Name:        "fairness",
+				Description: "Share nomination slots fairly between nominators",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "enabled",
+						Description: "Take one nomination per nominator, favouring those left out of recent polls",
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Required:    true,
+					},
+				},
+			},
+			{
+				Name:        "tournament",
+				Description: "Start a bracket tournament of head-to-head polls. The champion becomes the FoW",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "size",
+						Description: "Number of entries seeded from nominations and random picks",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "8",
+								Value: 8,
+							},
+							{
+								Name:  "16",
+								Value: 16,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:        "early-close",
+				Description: "End polls once everyone has voted or the lead can't be beaten",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "enabled",
+						Description: "End polls early",
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Required:    true,
+					},
+					{
+						Name:        "role",
+						Description: "Role whose members are the eligible voters. Defaults to every member",
+						Type:        discordgo.ApplicationCommandOptionRole,
+					},
+					{
+						Name:        "voters",
+						Description: "Number of eligible voters. Overrides the role",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						MinValue:    Ptr(1.0),
+					},
+				},
+			},
+			{
+				Name:        "voting",
+				Description: "Choose how members vote in polls",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "mode",
+						Description: "Voting mode",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Required:    true,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Native poll",
+								Value: "native",
+							},
+							{
+								Name:  "Ranked choice",
+								Value: "ranked",
+							},
+							{
+								Name:  "Hidden results",
+								Value: "hidden",
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:        "quorum",
+				Description: "Set the turnout a poll needs to pick a winner. Leave empty to remove",
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Name:        "min-votes",
+						Description: "Minimum number of voters",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						MinValue:    Ptr(1.0),
+					},
+					{
+						Name:        "role",
+						Description: "Role whose members are counted for the percentage",
+						Type:        discordgo.ApplicationCommandOptionRole,
+					},
+					{
+						Name:        "percent",
+						Description: "Percentage of the role that must vote",
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						MinValue:    Ptr(1.0),
+						MaxValue:    100,
+					},
+					{
+						Name:        "action",
+						Description: "What happens when the quorum isn't met",
+						Type:        discordgo.ApplicationCommandOptionString,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "Extend the poll",
+								Value: "extend",
+							},
+							{
+								Name:  "Start a new poll",
+								Value: "new-poll",
+							},
+							{
+								Name:  "Keep the current flavor of the week",
+								Value: "keep-fow",
+							},
+						},
+					},
+				},
+			},
+		},
+	},
 	{
 		Name:                     "start-poll",
 		Description:              "Start a poll",
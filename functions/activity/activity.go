@@ -9,6 +9,7 @@ import (
 	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"cloud.google.com/go/firestore"
 	"cloud.google.com/go/firestore/apiv1/firestorepb"
@@ -24,6 +25,10 @@ import (
 
 const PAGE_SIZE int = 5
 
+const MAX_TAG_LENGTH int = 32
+
+const MAX_ALIAS_LENGTH int = 32
+
 type ActivityType string
 
 const (
@@ -37,6 +42,7 @@ const (
 	ALREADY_EXISTS        = "already exists"
 	DOES_NOT_EXIST        = "does not exist"
 	STILL_HAS_NOMINATIONS = "still has nominations"
+	ALIAS_TAKEN           = "alias taken"
 )
 
 type ActivityError struct {
@@ -78,6 +84,32 @@ type InnerActivity struct {
 	NominationsCount int          `firestore:"nominations_count"`
 	Random           randomHelper `firestore:"random"`
 	GameInfo         *GameInfo    `firestore:"game_info"`
+	CreatedAt        time.Time    `firestore:"created_at"`
+	FowCount         int          `firestore:"fow_count"`
+	LastFow          *time.Time   `firestore:"last_fow"`
+	Tags             []string     `firestore:"tags"`
+	// Stored in search form
+	Aliases []string `firestore:"aliases"`
+	// User ID of who added the activity. Empty for older activities
+	CreatedBy string `firestore:"created_by"`
+	// 0 when the player count is unknown
+	MinPlayers int      `firestore:"min_players"`
+	MaxPlayers int      `firestore:"max_players"`
+	Owners     []string `firestore:"owners"`
+	// User ID to their rating of the activity as a flavor of the week
+	Ratings     map[string]Rating `firestore:"ratings"`
+	Description string            `firestore:"description"`
+	Links       []string          `firestore:"links"`
+	// Replaces the provider emoji in polls
+	Emoji string `firestore:"emoji"`
+	// Replaces the game's background image
+	CustomImage string `firestore:"image_url"`
+}
+
+type Rating struct {
+	Score   int       `firestore:"score"`
+	Comment string    `firestore:"comment"`
+	RatedAt time.Time `firestore:"rated_at"`
 }
 
 type Activity struct {
@@ -96,12 +128,28 @@ func getCollection(cl *clients.Clients) (*firestore.CollectionRef, error) {
 }
 
 func GetActivity(ctx context.Context, name, guildID string, cl *clients.Clients) (*Activity, error) {
+	act, err := GetActivityByID(ctx, generateName(guildID, name), cl)
+	if err == nil {
+		return act, nil
+	}
+	ae, ok := err.(*ActivityError)
+	if !ok || ae.Reason != DOES_NOT_EXIST {
+		return nil, err
+	}
+	// Document IDs hash the exact name but names differing in case or spacing are the same activity
 	activityCollection, err := getCollection(cl)
 	if err != nil {
 		return nil, fmt.Errorf("getCollection: %v", err)
 	}
-	docName := generateName(guildID, name)
-	activityDoc := activityCollection.Doc(docName)
+	return getFirstActivity(ctx, guildQuery(activityCollection, guildID, "search_name", "==", SearchName(name)))
+}
+
+func GetActivityByID(ctx context.Context, activityID string, cl *clients.Clients) (*Activity, error) {
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	activityDoc := activityCollection.Doc(activityID)
 	activityDocSnap, err := activityDoc.Get(ctx)
 	if err != nil {
 		if status.Code(err) == codes.NotFound {
@@ -110,7 +158,7 @@ func GetActivity(ctx context.Context, name, guildID string, cl *clients.Clients)
 		return nil, err
 	}
 	act := Activity{
-		docName:    docName,
+		docName:    activityID,
 		docRef:     activityDoc,
 		updateTime: activityDocSnap.UpdateTime,
 	}
@@ -121,11 +169,251 @@ func GetActivity(ctx context.Context, name, guildID string, cl *clients.Clients)
 	return &act, nil
 }
 
+// ResolveActivity returns the activity with the name or, failing that, the alias
+func ResolveActivity(ctx context.Context, name, guildID string, cl *clients.Clients) (*Activity, error) {
+	act, err := GetActivity(ctx, name, guildID, cl)
+	if err == nil {
+		return act, nil
+	}
+	ae, ok := err.(*ActivityError)
+	if !ok || ae.Reason != DOES_NOT_EXIST {
+		return nil, err
+	}
+	return GetActivityByAlias(ctx, name, guildID, cl)
+}
+
+// GetActivityByAlias returns the activity known by the alias
+func GetActivityByAlias(ctx context.Context, alias, guildID string, cl *clients.Clients) (*Activity, error) {
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	return getFirstActivity(ctx, guildQuery(activityCollection, guildID, "aliases", "array-contains", SearchName(alias)))
+}
+
+func getFirstActivity(ctx context.Context, query firestore.Query) (*Activity, error) {
+	docs, err := query.Limit(1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("getAll: %v", err)
+	}
+	if len(docs) == 0 {
+		return nil, NewActivityError(DOES_NOT_EXIST)
+	}
+	act := Activity{
+		docName:    docs[0].Ref.ID,
+		docRef:     docs[0].Ref,
+		updateTime: docs[0].UpdateTime,
+	}
+	err = docs[0].DataTo(&act.inner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deserialize activity: %v", err)
+	}
+	return &act, nil
+}
+
+// guildQuery filters the guild's activities on a single field
+func guildQuery(activityCollection *firestore.CollectionRef, guildID, path, operator string, value interface{}) firestore.Query {
+	return activityCollection.WhereEntity(firestore.AndFilter{
+		Filters: []firestore.EntityFilter{
+			firestore.PropertyFilter{
+				Path:     "guild_id",
+				Operator: "==",
+				Value:    guildID,
+			},
+			firestore.PropertyFilter{
+				Path:     path,
+				Operator: operator,
+				Value:    value,
+			},
+		},
+	})
+}
+
+// identityQueries find the activities the name or game would duplicate
+func identityQueries(activityCollection *firestore.CollectionRef, guildID, name string, gameInfo *GameInfo) []firestore.Query {
+	searchName := SearchName(name)
+	queries := []firestore.Query{
+		guildQuery(activityCollection, guildID, "search_name", "==", searchName),
+		guildQuery(activityCollection, guildID, "aliases", "array-contains", searchName),
+	}
+	if gameInfo != nil {
+		queries = append(queries, guildQuery(activityCollection, guildID, "game_info.slug", "==", gameInfo.Slug))
+	}
+	return queries
+}
+
+// findExisting returns the name of the activity the name or game would duplicate or an empty string.
+// The activity with the document except is ignored. Reads are part of tx when it is set
+func findExisting(ctx context.Context, tx *firestore.Transaction, activityCollection *firestore.CollectionRef, guildID, name string, gameInfo *GameInfo, except string) (string, error) {
+	for _, query := range identityQueries(activityCollection, guildID, name, gameInfo) {
+		query = query.Limit(2)
+		var docs []*firestore.DocumentSnapshot
+		var err error
+		if tx != nil {
+			docs, err = tx.Documents(query).GetAll()
+		} else {
+			docs, err = query.Documents(ctx).GetAll()
+		}
+		if err != nil {
+			return "", fmt.Errorf("getAll: %v", err)
+		}
+		for _, doc := range docs {
+			if doc.Ref.ID == except {
+				continue
+			}
+			existing, _ := doc.Data()["name"].(string)
+			return existing, nil
+		}
+	}
+	return "", nil
+}
+
+// FindExisting returns the name of the activity the name or game would duplicate or an empty string
+func FindExisting(ctx context.Context, guildID, name string, gameInfo *GameInfo, cl *clients.Clients) (string, error) {
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return "", fmt.Errorf("getCollection: %v", err)
+	}
+	return findExisting(ctx, nil, activityCollection, guildID, name, gameInfo, "")
+}
+
+// GetActivityID returns the document ID of the named activity
+func GetActivityID(guildID, name string) string {
+	return generateName(guildID, name)
+}
+
 func generateName(guildId, name string) string {
 	return fmt.Sprintf("%v:%x", guildId, sha256.Sum256([]byte(name)))
 }
 
-func Create(ctx context.Context, typ ActivityType, name, guildID string, gameInfo *GameInfo, cl *clients.Clients) (*Activity, error) {
+func (act *Activity) ID() string {
+	return act.docName
+}
+
+func (act *Activity) Name() string {
+	return act.inner.Name
+}
+
+func (act *Activity) Typ() ActivityType {
+	return act.inner.Typ
+}
+
+func (act *Activity) Nominations() []string {
+	return act.inner.Nominations
+}
+
+// FowStats returns how many times the activity was the flavor of the week and when it last was
+func (act *Activity) FowStats() (int, *time.Time) {
+	return act.inner.FowCount, act.inner.LastFow
+}
+
+// ImageURL returns the custom image or the game's background image
+func (act *Activity) ImageURL() string {
+	return act.inner.imageURL()
+}
+
+func (inAct *InnerActivity) imageURL() string {
+	if inAct.CustomImage != "" {
+		return inAct.CustomImage
+	}
+	if inAct.GameInfo == nil {
+		return ""
+	}
+	return inAct.GameInfo.BackgroundImage
+}
+
+func (act *Activity) GameInfo() *GameInfo {
+	return act.inner.GameInfo
+}
+
+func (act *Activity) Details() (string, []string, string) {
+	return act.inner.Description, act.inner.Links, act.inner.CustomImage
+}
+
+func (act *Activity) SetDetails(ctx context.Context, description string, links []string, imageURL string) error {
+	_, err := act.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "description",
+			Value: description,
+		},
+		{
+			Path:  "links",
+			Value: links,
+		},
+		{
+			Path:  "image_url",
+			Value: imageURL,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	act.inner.Description = description
+	act.inner.Links = links
+	act.inner.CustomImage = imageURL
+	return nil
+}
+
+func (act *Activity) Emoji() string {
+	return act.inner.Emoji
+}
+
+// SetEmoji sets the poll emoji of the activity. An empty emoji uses the provider's emoji
+func (act *Activity) SetEmoji(ctx context.Context, emoji string) error {
+	_, err := act.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "emoji",
+			Value: emoji,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	act.inner.Emoji = emoji
+	return nil
+}
+
+// SetType changes the activity type and links it to a game. Activities can't be linked or owned.
+func (act *Activity) SetType(ctx context.Context, typ ActivityType, gameInfo *GameInfo) error {
+	updates := []firestore.Update{
+		{
+			Path:  "type",
+			Value: typ,
+		},
+	}
+	if gameInfo != nil {
+		updates = append(updates, firestore.Update{
+			Path:  "game_info",
+			Value: gameInfo,
+		})
+	} else if typ == ACTIVITY {
+		updates = append(updates, firestore.Update{
+			Path:  "game_info",
+			Value: firestore.Delete,
+		}, firestore.Update{
+			Path:  "owners",
+			Value: firestore.Delete,
+		})
+	}
+	_, err := act.docRef.Update(ctx, updates)
+	if err != nil {
+		return err
+	}
+	act.inner.Typ = typ
+	if gameInfo != nil {
+		act.inner.GameInfo = gameInfo
+	} else if typ == ACTIVITY {
+		act.inner.GameInfo = nil
+		act.inner.Owners = nil
+	}
+	return nil
+}
+
+func Create(ctx context.Context, typ ActivityType, name, guildID string, gameInfo *GameInfo, tags []string, minPlayers, maxPlayers int, createdBy string, cl *clients.Clients) (*Activity, error) {
+	firestoreClient, err := cl.Firestore()
+	if err != nil {
+		return nil, fmt.Errorf("firestore: %v", err)
+	}
 	activityCollection, err := getCollection(cl)
 	if err != nil {
 		return nil, fmt.Errorf("getCollection: %v", err)
@@ -136,25 +424,91 @@ func Create(ctx context.Context, typ ActivityType, name, guildID string, gameInf
 	inAct := InnerActivity{
 		Typ:        typ,
 		Name:       name,
-		SearchName: strings.ToLower(name),
+		SearchName: SearchName(name),
 		GuildID:    guildID,
 		Random:     NewRandomHelper(),
 		GameInfo:   gameInfo,
+		CreatedAt:  time.Now().UTC(),
+		CreatedBy:  createdBy,
+		Tags:       tags,
+		MinPlayers: minPlayers,
+		MaxPlayers: maxPlayers,
 	}
 	ctxzap.Info(ctx, fmt.Sprintf("Creating %v in %v", name, guildID))
-	wr, err := activityDoc.Create(ctx, &inAct)
+	// The duplicate check is in the transaction so concurrent adds of the same name can't both succeed
+	err = firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		existing, err := findExisting(ctx, tx, activityCollection, guildID, name, gameInfo, "")
+		if err != nil {
+			return fmt.Errorf("findExisting: %v", err)
+		}
+		if existing != "" {
+			return NewActivityError(ALREADY_EXISTS)
+		}
+		return tx.Create(activityDoc, &inAct)
+	})
 	if err != nil {
+		if _, ok := err.(*ActivityError); ok {
+			return nil, err
+		}
 		if status.Code(err) == codes.AlreadyExists {
 			return nil, NewActivityError(ALREADY_EXISTS)
 		}
-		return nil, fmt.Errorf("activityDoc.Create: %v", err)
+		return nil, fmt.Errorf("runTransaction: %v", err)
 	}
-	return &Activity{
-		docName:    docName,
-		inner:      inAct,
-		docRef:     activityDoc,
-		updateTime: wr.UpdateTime,
-	}, nil
+	// The update time is needed for removal preconditions
+	return GetActivityByID(ctx, docName, cl)
+}
+
+// Rename moves the activity to the document of its new name. Everything but the name is kept.
+// inTx runs in the same transaction to update references to the activity. It must read before writing
+func Rename(ctx context.Context, guildID, oldName, newName string, inTx func(tx *firestore.Transaction) error, cl *clients.Clients) error {
+	firestoreClient, err := cl.Firestore()
+	if err != nil {
+		return fmt.Errorf("firestore: %v", err)
+	}
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return fmt.Errorf("getCollection: %v", err)
+	}
+	oldDoc := activityCollection.Doc(generateName(guildID, oldName))
+	newDoc := activityCollection.Doc(generateName(guildID, newName))
+	ctxzap.Info(ctx, fmt.Sprintf("Renaming %v to %v in %v", oldName, newName, guildID))
+	return firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		oldSnap, err := tx.Get(oldDoc)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return NewActivityError(DOES_NOT_EXIST)
+			}
+			return fmt.Errorf("get: %v", err)
+		}
+		_, err = tx.Get(newDoc)
+		if err == nil {
+			return NewActivityError(ALREADY_EXISTS)
+		}
+		if status.Code(err) != codes.NotFound {
+			return fmt.Errorf("get: %v", err)
+		}
+		existing, err := findExisting(ctx, tx, activityCollection, guildID, newName, nil, oldDoc.ID)
+		if err != nil {
+			return fmt.Errorf("findExisting: %v", err)
+		}
+		if existing != "" {
+			return NewActivityError(ALREADY_EXISTS)
+		}
+		err = inTx(tx)
+		if err != nil {
+			return err
+		}
+		// Copy the raw document so fields missing from InnerActivity aren't lost
+		data := oldSnap.Data()
+		data["name"] = newName
+		data["search_name"] = SearchName(newName)
+		err = tx.Create(newDoc, data)
+		if err != nil {
+			return fmt.Errorf("create: %v", err)
+		}
+		return tx.Delete(oldDoc)
+	})
 }
 
 func (act *Activity) RemoveActivity(ctx context.Context, force bool) error {
@@ -170,68 +524,468 @@ func (act *Activity) RemoveActivity(ctx context.Context, force bool) error {
 	}
 	_, err := act.docRef.Delete(ctx, firestore.LastUpdateTime(act.updateTime))
 	if err != nil {
-		return fmt.Errorf("failed to delete %v (%v)", act.docName, act.inner.Name)
+		return fmt.Errorf("failed to delete %v (%v)", act.docName, act.inner.Name)
+	}
+	return nil
+}
+
+func (act *Activity) AddNomination(ctx context.Context, userId string) error {
+	if slices.Contains(act.inner.Nominations, userId) {
+		return nil
+	}
+	_, err := act.docRef.Update(ctx,
+		[]firestore.Update{
+			{
+				FieldPath: firestore.FieldPath{"nominations"},
+				Value:     firestore.ArrayUnion(userId),
+			},
+			{
+				FieldPath: firestore.FieldPath{"nominations_count"},
+				Value:     firestore.Increment(1),
+			},
+			{
+				FieldPath: firestore.FieldPath{"random"},
+				Value:     NewRandomHelper(),
+			},
+		},
+	)
+	act.inner.Nominations = append(act.inner.Nominations, userId)
+	act.inner.NominationsCount += 1
+	return err
+}
+
+func (act *Activity) RemoveNomination(ctx context.Context, userId string) error {
+	if !slices.Contains(act.inner.Nominations, userId) {
+		return nil
+	}
+	_, err := act.docRef.Update(ctx,
+		[]firestore.Update{
+			{
+				FieldPath: firestore.FieldPath{"nominations"},
+				Value:     firestore.ArrayRemove(userId),
+			},
+			{
+				FieldPath: firestore.FieldPath{"nominations_count"},
+				Value:     firestore.Increment(-1),
+			},
+			{
+				FieldPath: firestore.FieldPath{"random"},
+				Value:     NewRandomHelper(),
+			},
+		},
+	)
+	act.inner.Nominations = slices.DeleteFunc(act.inner.Nominations, func(cmp string) bool {
+		return cmp == userId
+	})
+	act.inner.NominationsCount -= 1
+	return err
+}
+
+func (act *Activity) Owners() []string {
+	return act.inner.Owners
+}
+
+func (act *Activity) AddOwner(ctx context.Context, userId string) error {
+	if slices.Contains(act.inner.Owners, userId) {
+		return nil
+	}
+	_, err := act.docRef.Update(ctx,
+		[]firestore.Update{
+			{
+				FieldPath: firestore.FieldPath{"owners"},
+				Value:     firestore.ArrayUnion(userId),
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	act.inner.Owners = append(act.inner.Owners, userId)
+	return nil
+}
+
+func (act *Activity) RemoveOwner(ctx context.Context, userId string) error {
+	if !slices.Contains(act.inner.Owners, userId) {
+		return nil
+	}
+	_, err := act.docRef.Update(ctx,
+		[]firestore.Update{
+			{
+				FieldPath: firestore.FieldPath{"owners"},
+				Value:     firestore.ArrayRemove(userId),
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	act.inner.Owners = slices.DeleteFunc(act.inner.Owners, func(cmp string) bool {
+		return cmp == userId
+	})
+	return nil
+}
+
+func (act *Activity) Ratings() map[string]Rating {
+	return act.inner.Ratings
+}
+
+// SetRating stores a user's rating, replacing their previous one
+func (act *Activity) SetRating(ctx context.Context, userId string, rating Rating) error {
+	_, err := act.docRef.Update(ctx,
+		[]firestore.Update{
+			{
+				FieldPath: firestore.FieldPath{"ratings", userId},
+				Value:     rating,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if act.inner.Ratings == nil {
+		act.inner.Ratings = make(map[string]Rating)
+	}
+	act.inner.Ratings[userId] = rating
+	return nil
+}
+
+func (act *Activity) MarkFow(ctx context.Context) error {
+	now := time.Now().UTC()
+	_, err := act.docRef.Update(ctx,
+		[]firestore.Update{
+			{
+				FieldPath: firestore.FieldPath{"fow_count"},
+				Value:     firestore.Increment(1),
+			},
+			{
+				FieldPath: firestore.FieldPath{"last_fow"},
+				Value:     now,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	act.inner.FowCount += 1
+	act.inner.LastFow = &now
+	return nil
+}
+
+func (act *Activity) PlayerCount() (int, int) {
+	return act.inner.MinPlayers, act.inner.MaxPlayers
+}
+
+func (act *Activity) SetPlayerCount(ctx context.Context, minPlayers, maxPlayers int) error {
+	_, err := act.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "min_players",
+			Value: minPlayers,
+		},
+		{
+			Path:  "max_players",
+			Value: maxPlayers,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	act.inner.MinPlayers = minPlayers
+	act.inner.MaxPlayers = maxPlayers
+	return nil
+}
+
+// FitsPlayers returns whether the activity can be played by the number of players.
+// Unknown limits always fit.
+func (inAct *InnerActivity) FitsPlayers(players int) bool {
+	return (inAct.MinPlayers == 0 || players >= inAct.MinPlayers) && (inAct.MaxPlayers == 0 || players <= inAct.MaxPlayers)
+}
+
+// FormatPlayerCount describes a player count range. Returns an empty string when it is unknown
+func FormatPlayerCount(minPlayers, maxPlayers int) string {
+	switch {
+	case minPlayers == 0 && maxPlayers == 0:
+		return ""
+	case maxPlayers == 0:
+		return fmt.Sprintf("%v+", minPlayers)
+	case minPlayers == maxPlayers:
+		return fmt.Sprint(minPlayers)
+	}
+	return fmt.Sprintf("%v-%v", max(minPlayers, 1), maxPlayers)
+}
+
+// FormatRating describes the average rating of an activity. Returns an empty string when it hasn't been rated
+func FormatRating(ratings map[string]Rating) string {
+	if len(ratings) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("⭐ %.1f (%v ratings)", AverageRating(ratings), len(ratings))
+}
+
+// AverageRating returns the mean score of the ratings. 0 when there are none
+func AverageRating(ratings map[string]Rating) float64 {
+	if len(ratings) == 0 {
+		return 0
+	}
+	total := 0
+	for _, rating := range ratings {
+		total += rating.Score
+	}
+	return float64(total) / float64(len(ratings))
+}
+
+// ownerCount returns the number of owners of a game. Activities can't be owned
+func ownerCount(inAct *InnerActivity) *int {
+	if inAct.Typ != GAME {
+		return nil
+	}
+	return firestore.Ptr(len(inAct.Owners))
+}
+
+// GetInnerActivities returns the activities with the given names. Names that don't exist are left out.
+func GetInnerActivities(ctx context.Context, guildID string, names []string, cl *clients.Clients) (map[string]*InnerActivity, error) {
+	results := make(map[string]*InnerActivity)
+	if len(names) == 0 {
+		return results, nil
+	}
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	firestoreClient, err := cl.Firestore()
+	if err != nil {
+		return nil, fmt.Errorf("firestore: %v", err)
+	}
+	docs := make([]*firestore.DocumentRef, 0, len(names))
+	for _, name := range names {
+		docs = append(docs, activityCollection.Doc(generateName(guildID, name)))
+	}
+	snaps, err := firestoreClient.GetAll(ctx, docs)
+	if err != nil {
+		return nil, fmt.Errorf("getAll: %v", err)
+	}
+	for i, snap := range snaps {
+		if !snap.Exists() {
+			continue
+		}
+		var inAct InnerActivity
+		err = snap.DataTo(&inAct)
+		if err != nil {
+			return nil, fmt.Errorf("doc.DataTo: %v", err)
+		}
+		results[names[i]] = &inAct
+	}
+	return results, nil
+}
+
+// Added returns who added the activity and when
+func (act *Activity) Added() (string, time.Time) {
+	return act.inner.CreatedBy, act.inner.CreatedAt
+}
+
+// FormatAdded describes when and by whom an activity was added. Returns an empty string when it is unknown
+func FormatAdded(createdBy string, createdAt time.Time) string {
+	if createdAt.IsZero() {
+		return ""
+	}
+	if createdBy == "" {
+		return fmt.Sprintf("<t:%v:D>", createdAt.Unix())
+	}
+	return fmt.Sprintf("<t:%v:D> by <@%v>", createdAt.Unix(), createdBy)
+}
+
+func (act *Activity) Tags() []string {
+	return act.inner.Tags
+}
+
+// SetTags replaces all of the activity's tags
+func (act *Activity) SetTags(ctx context.Context, tags []string) error {
+	_, err := act.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "tags",
+			Value: tags,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	act.inner.Tags = tags
+	return nil
+}
+
+func (act *Activity) AddTags(ctx context.Context, tags []string) error {
+	values := make([]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		values = append(values, tag)
+	}
+	_, err := act.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "tags",
+			Value: firestore.ArrayUnion(values...),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if !slices.Contains(act.inner.Tags, tag) {
+			act.inner.Tags = append(act.inner.Tags, tag)
+		}
+	}
+	return nil
+}
+
+func (act *Activity) RemoveTags(ctx context.Context, tags []string) error {
+	values := make([]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		values = append(values, tag)
+	}
+	_, err := act.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "tags",
+			Value: firestore.ArrayRemove(values...),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	act.inner.Tags = slices.DeleteFunc(act.inner.Tags, func(tag string) bool {
+		return slices.Contains(tags, tag)
+	})
+	return nil
+}
+
+func (act *Activity) Aliases() []string {
+	return act.inner.Aliases
+}
+
+// AddAliases adds the aliases if no other activity of the guild is named or known by them
+func (act *Activity) AddAliases(ctx context.Context, aliases []string, cl *clients.Clients) error {
+	firestoreClient, err := cl.Firestore()
+	if err != nil {
+		return fmt.Errorf("firestore: %v", err)
+	}
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return fmt.Errorf("getCollection: %v", err)
+	}
+	values := make([]interface{}, 0, len(aliases))
+	for _, alias := range aliases {
+		values = append(values, alias)
+	}
+	// The checks and update are in a transaction so two activities can't claim the same alias
+	err = firestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		for _, alias := range aliases {
+			docs, err := tx.Documents(guildQuery(activityCollection, act.inner.GuildID, "aliases", "array-contains", alias)).GetAll()
+			if err != nil {
+				return fmt.Errorf("getAll: %v", err)
+			}
+			for _, doc := range docs {
+				if doc.Ref.ID != act.docName {
+					return NewActivityError(ALIAS_TAKEN)
+				}
+			}
+			named, err := tx.Documents(guildQuery(activityCollection, act.inner.GuildID, "search_name", "==", alias)).GetAll()
+			if err != nil {
+				return fmt.Errorf("getAll: %v", err)
+			}
+			for _, doc := range named {
+				if doc.Ref.ID != act.docName {
+					return NewActivityError(ALIAS_TAKEN)
+				}
+			}
+		}
+		return tx.Update(act.docRef, []firestore.Update{
+			{
+				Path:  "aliases",
+				Value: firestore.ArrayUnion(values...),
+			},
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for _, alias := range aliases {
+		if !slices.Contains(act.inner.Aliases, alias) {
+			act.inner.Aliases = append(act.inner.Aliases, alias)
+		}
+	}
+	return nil
+}
+
+func (act *Activity) RemoveAliases(ctx context.Context, aliases []string) error {
+	values := make([]interface{}, 0, len(aliases))
+	for _, alias := range aliases {
+		values = append(values, alias)
+	}
+	_, err := act.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "aliases",
+			Value: firestore.ArrayRemove(values...),
+		},
+	})
+	if err != nil {
+		return err
 	}
+	act.inner.Aliases = slices.DeleteFunc(act.inner.Aliases, func(alias string) bool {
+		return slices.Contains(aliases, alias)
+	})
 	return nil
 }
 
-func (act *Activity) AddNomination(ctx context.Context, userId string) error {
-	if slices.Contains(act.inner.Nominations, userId) {
-		return nil
+// NormalizeAliases splits a comma separated list into aliases in search form without duplicates.
+// Aliases that are too long are returned separately.
+func NormalizeAliases(raw string) ([]string, []string) {
+	aliases := make([]string, 0)
+	tooLong := make([]string, 0)
+	for _, alias := range strings.Split(raw, ",") {
+		alias = SearchName(alias)
+		if alias == "" || slices.Contains(aliases, alias) {
+			continue
+		}
+		if utf8.RuneCountInString(alias) > MAX_ALIAS_LENGTH {
+			tooLong = append(tooLong, alias)
+			continue
+		}
+		aliases = append(aliases, alias)
 	}
-	_, err := act.docRef.Update(ctx,
-		[]firestore.Update{
-			{
-				FieldPath: firestore.FieldPath{"nominations"},
-				Value:     firestore.ArrayUnion(userId),
-			},
-			{
-				FieldPath: firestore.FieldPath{"nominations_count"},
-				Value:     firestore.Increment(1),
-			},
-			{
-				FieldPath: firestore.FieldPath{"random"},
-				Value:     NewRandomHelper(),
-			},
-		},
-	)
-	act.inner.Nominations = append(act.inner.Nominations, userId)
-	act.inner.NominationsCount += 1
-	return err
+	return aliases, tooLong
 }
 
-func (act *Activity) RemoveNomination(ctx context.Context, userId string) error {
-	if !slices.Contains(act.inner.Nominations, userId) {
-		return nil
+// NormalizeName trims the name and collapses repeated whitespace
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// SearchName returns the form of the name used to identify and search activities
+func SearchName(name string) string {
+	return strings.ToLower(NormalizeName(name))
+}
+
+// NormalizeTags splits a comma separated list into lowercase tags without duplicates
+func NormalizeTags(raw string) []string {
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(raw, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || slices.Contains(tags, tag) {
+			continue
+		}
+		if runes := []rune(tag); len(runes) > MAX_TAG_LENGTH {
+			tag = string(runes[:MAX_TAG_LENGTH])
+		}
+		tags = append(tags, tag)
 	}
-	_, err := act.docRef.Update(ctx,
-		[]firestore.Update{
-			{
-				FieldPath: firestore.FieldPath{"nominations"},
-				Value:     firestore.ArrayRemove(userId),
-			},
-			{
-				FieldPath: firestore.FieldPath{"nominations_count"},
-				Value:     firestore.Increment(-1),
-			},
-			{
-				FieldPath: firestore.FieldPath{"random"},
-				Value:     NewRandomHelper(),
-			},
-		},
-	)
-	act.inner.Nominations = slices.DeleteFunc(act.inner.Nominations, func(cmp string) bool {
-		return cmp == userId
-	})
-	act.inner.NominationsCount -= 1
-	return err
+	return tags
 }
 
 type ActivitesPageOptions struct {
 	Name            string
 	Type            ActivityType
+	Tag             string
+	OwnerId         string
 	NominationsOnly bool
 	UserId          string
+	// Order by newest instead of name. Activities added before created_at was recorded are listed last by name
+	Newest bool
 }
 
 func GetActivitiesPage(ctx context.Context, guildID string, pageNum int, opts *ActivitesPageOptions, cl *clients.Clients) ([]utils.GameEntry, bool, error) {
@@ -245,9 +999,12 @@ func GetActivitiesPage(ctx context.Context, guildID string, pageNum int, opts *A
 			}
 			return nil, false, fmt.Errorf("getActivity: %v", err)
 		}
-		imageUrl := ""
-		if act.inner.GameInfo != nil {
-			imageUrl = act.inner.GameInfo.BackgroundImage
+		imageUrl := act.inner.imageURL()
+		if opts.Tag != "" && !slices.Contains(act.inner.Tags, opts.Tag) {
+			return []utils.GameEntry{}, true, nil
+		}
+		if opts.OwnerId != "" && !slices.Contains(act.inner.Owners, opts.OwnerId) {
+			return []utils.GameEntry{}, true, nil
 		}
 		if !opts.NominationsOnly || slices.Contains(act.inner.Nominations, opts.UserId) {
 			return []utils.GameEntry{
@@ -255,6 +1012,11 @@ func GetActivitiesPage(ctx context.Context, guildID string, pageNum int, opts *A
 					Name:        opts.Name,
 					Nominations: firestore.Ptr(len(act.inner.Nominations)),
 					ImageURL:    imageUrl,
+					Tags:        act.inner.Tags,
+					Players:     FormatPlayerCount(act.inner.MinPlayers, act.inner.MaxPlayers),
+					Owners:      ownerCount(&act.inner),
+					Rating:      FormatRating(act.inner.Ratings),
+					Added:       FormatAdded(act.inner.CreatedBy, act.inner.CreatedAt),
 				},
 			}, true, nil
 		}
@@ -266,7 +1028,7 @@ func GetActivitiesPage(ctx context.Context, guildID string, pageNum int, opts *A
 		return nil, false, fmt.Errorf("getCollection: %v", err)
 	}
 	// This query requires an index which is created in terraform
-	query := activityCollection.Select("name", "nominations", "game_info")
+	query := activityCollection.Select("name", "search_name", "type", "nominations", "game_info", "tags", "min_players", "max_players", "owners", "ratings", "image_url", "created_at", "created_by")
 	query = query.WhereEntity(firestore.PropertyFilter{
 		Path:     "guild_id",
 		Operator: "==",
@@ -279,6 +1041,21 @@ func GetActivitiesPage(ctx context.Context, guildID string, pageNum int, opts *A
 			Value:    opts.Type,
 		})
 	}
+	// Only one array-contains filter is allowed so tags, owners and a user's nominations can't be combined
+	if opts.Tag != "" {
+		query = query.WhereEntity(firestore.PropertyFilter{
+			Path:     "tags",
+			Operator: "array-contains",
+			Value:    opts.Tag,
+		})
+	}
+	if opts.OwnerId != "" {
+		query = query.WhereEntity(firestore.PropertyFilter{
+			Path:     "owners",
+			Operator: "array-contains",
+			Value:    opts.OwnerId,
+		})
+	}
 	if opts.NominationsOnly {
 		if opts.UserId == "" {
 			query = query.WhereEntity(firestore.PropertyFilter{
@@ -294,6 +1071,8 @@ func GetActivitiesPage(ctx context.Context, guildID string, pageNum int, opts *A
 			})
 		}
 		query = query.OrderBy("nominations_count", firestore.Desc)
+	} else if opts.Newest {
+		return getNewestPage(ctx, query, pageNum)
 	} else {
 		query = query.OrderBy("search_name", firestore.Asc)
 	}
@@ -314,15 +1093,7 @@ func GetActivitiesPage(ctx context.Context, guildID string, pageNum int, opts *A
 		if err != nil {
 			return nil, false, fmt.Errorf("doc.DataTo: %v", err)
 		}
-		imageUrl := ""
-		if inAct.GameInfo != nil {
-			imageUrl = inAct.GameInfo.BackgroundImage
-		}
-		results = append(results, utils.GameEntry{
-			Name:        inAct.Name,
-			Nominations: firestore.Ptr(len(inAct.Nominations)),
-			ImageURL:    imageUrl,
-		})
+		results = append(results, poolEntry(&inAct))
 	}
 	lastItem := false
 	_, err = iter.Next()
@@ -335,7 +1106,59 @@ func GetActivitiesPage(ctx context.Context, guildID string, pageNum int, opts *A
 
 }
 
-func AutocompleteActivities(ctx context.Context, guildID, text string, cl *clients.Clients) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+// getNewestPage sorts the matching activities in memory since ordering by created_at
+// would drop the activities added before it was recorded. Those are listed last by name
+func getNewestPage(ctx context.Context, query firestore.Query, pageNum int) ([]utils.GameEntry, bool, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	matching := make([]InnerActivity, 0)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("iter.Next: %v", err)
+		}
+		var inAct InnerActivity
+		err = doc.DataTo(&inAct)
+		if err != nil {
+			return nil, false, fmt.Errorf("doc.DataTo: %v", err)
+		}
+		matching = append(matching, inAct)
+	}
+	slices.SortFunc(matching, func(a, b InnerActivity) int {
+		if byAge := b.CreatedAt.Compare(a.CreatedAt); byAge != 0 {
+			return byAge
+		}
+		return strings.Compare(a.SearchName, b.SearchName)
+	})
+
+	start := min(pageNum*PAGE_SIZE, len(matching))
+	end := min(start+PAGE_SIZE, len(matching))
+	results := make([]utils.GameEntry, 0, PAGE_SIZE)
+	for i := start; i < end; i++ {
+		results = append(results, poolEntry(&matching[i]))
+	}
+	return results, end == len(matching), nil
+}
+
+func poolEntry(inAct *InnerActivity) utils.GameEntry {
+	return utils.GameEntry{
+		Name:        inAct.Name,
+		Nominations: firestore.Ptr(len(inAct.Nominations)),
+		ImageURL:    inAct.imageURL(),
+		Tags:        inAct.Tags,
+		Players:     FormatPlayerCount(inAct.MinPlayers, inAct.MaxPlayers),
+		Owners:      ownerCount(inAct),
+		Rating:      FormatRating(inAct.Ratings),
+		Added:       FormatAdded(inAct.CreatedBy, inAct.CreatedAt),
+	}
+}
+
+// autocompletePrefix returns the pool names starting with text
+func autocompletePrefix(ctx context.Context, guildID, text string, cl *clients.Clients) ([]*discordgo.ApplicationCommandOptionChoice, error) {
 	activityCollection, err := getCollection(cl)
 	if err != nil {
 		return []*discordgo.ApplicationCommandOptionChoice{}, fmt.Errorf("getCollection: %v", err)
@@ -417,7 +1240,12 @@ func GetTopNominations(ctx context.Context, guildID string, n int, cl *clients.C
 	return results, nil
 }
 
-func GetRandomActivities(ctx context.Context, guildID string, n int, cl *clients.Clients) ([]string, error) {
+type RandomOptions struct {
+	Type ActivityType
+	Tag  string
+}
+
+func GetRandomActivities(ctx context.Context, guildID string, n int, opts *RandomOptions, cl *clients.Clients) ([]string, error) {
 	activityCollection, err := getCollection(cl)
 	if err != nil {
 		return nil, fmt.Errorf("getCollection: %v", err)
@@ -434,7 +1262,55 @@ func GetRandomActivities(ctx context.Context, guildID string, n int, cl *clients
 		Path:     "guild_id",
 		Operator: "==",
 		Value:    guildID,
-	}).OrderBy(randomPath, firestore.Asc).Limit(n)
+	})
+	if opts.Type != "" {
+		query = query.WhereEntity(&firestore.PropertyFilter{
+			Path:     "type",
+			Operator: "==",
+			Value:    opts.Type,
+		})
+	}
+	if opts.Tag != "" {
+		query = query.WhereEntity(&firestore.PropertyFilter{
+			Path:     "tags",
+			Operator: "array-contains",
+			Value:    opts.Tag,
+		})
+	}
+	query = query.OrderBy(randomPath, firestore.Asc).Limit(n)
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	results := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iter.Next: %v", err)
+		}
+		var inAct InnerActivity
+		err = doc.DataTo(&inAct)
+		if err != nil {
+			return nil, fmt.Errorf("doc.DataTo: %v", err)
+		}
+		results = append(results, inAct.Name)
+	}
+	return results, nil
+}
+
+func GetNewestActivities(ctx context.Context, guildID string, n int, cl *clients.Clients) ([]string, error) {
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	// This query requires an index which is created in terraform
+	query := activityCollection.Select("name").WhereEntity(&firestore.PropertyFilter{
+		Path:     "guild_id",
+		Operator: "==",
+		Value:    guildID,
+	}).OrderBy("created_at", firestore.Desc).Limit(n)
 	iter := query.Documents(ctx)
 	defer iter.Stop()
 
@@ -457,6 +1333,188 @@ func GetRandomActivities(ctx context.Context, guildID string, n int, cl *clients
 	return results, nil
 }
 
+// GetOldestUnplayedActivities returns the oldest activities that have never been the FoW.
+// Activities added before creation times were tracked are treated as the oldest.
+func GetOldestUnplayedActivities(ctx context.Context, guildID string, n int, cl *clients.Clients) ([]string, error) {
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	// Older documents are missing created_at and fow_count so they can't be filtered on
+	query := activityCollection.Select("name", "created_at", "fow_count").WhereEntity(&firestore.PropertyFilter{
+		Path:     "guild_id",
+		Operator: "==",
+		Value:    guildID,
+	})
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	unplayed := make([]InnerActivity, 0)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iter.Next: %v", err)
+		}
+		var inAct InnerActivity
+		err = doc.DataTo(&inAct)
+		if err != nil {
+			return nil, fmt.Errorf("doc.DataTo: %v", err)
+		}
+		if inAct.FowCount == 0 {
+			unplayed = append(unplayed, inAct)
+		}
+	}
+	slices.SortStableFunc(unplayed, func(a, b InnerActivity) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	results := make([]string, 0, n)
+	for _, inAct := range unplayed[:min(n, len(unplayed))] {
+		results = append(results, inAct.Name)
+	}
+	return results, nil
+}
+
+// GetAllActivities returns every activity in the pool with its name, type, game info, FoW count and ratings
+func GetAllActivities(ctx context.Context, guildID string, cl *clients.Clients) ([]InnerActivity, error) {
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	query := activityCollection.Select("name", "search_name", "aliases", "type", "game_info", "fow_count", "ratings").WhereEntity(&firestore.PropertyFilter{
+		Path:     "guild_id",
+		Operator: "==",
+		Value:    guildID,
+	})
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	results := make([]InnerActivity, 0)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iter.Next: %v", err)
+		}
+		var inAct InnerActivity
+		err = doc.DataTo(&inAct)
+		if err != nil {
+			return nil, fmt.Errorf("doc.DataTo: %v", err)
+		}
+		results = append(results, inAct)
+	}
+	return results, nil
+}
+
+// FindSimilar returns the activities whose names are a few edits away from the name.
+// Exact duplicates are found by FindExisting
+func FindSimilar(ctx context.Context, guildID, name string, cl *clients.Clients) ([]string, error) {
+	pool, err := GetAllActivities(ctx, guildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getAllActivities: %v", err)
+	}
+	target := SearchName(name)
+	maxEdits := maxNameEdits(target)
+	similar := make([]string, 0)
+	for _, inAct := range pool {
+		// Activities created before names were normalized
+		if editDistance(SearchName(inAct.SearchName), target) <= maxEdits {
+			similar = append(similar, inAct.Name)
+		}
+	}
+	return similar, nil
+}
+
+// maxNameEdits returns how many edits apart names can be to be considered similar.
+// Short names are too easily confused to compare.
+func maxNameEdits(name string) int {
+	length := utf8.RuneCountInString(name)
+	if length <= 3 {
+		return 0
+	}
+	return min(max(length/5, 1), 3)
+}
+
+// editDistance returns the number of insertions, deletions, substitutions
+// and swaps of adjacent letters turning a into b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
+
+type NominatedActivity struct {
+	Name        string
+	Nominations []string
+	Tags        []string
+}
+
+// GetNominatedActivities returns every activity with a nomination ordered by nomination count
+func GetNominatedActivities(ctx context.Context, guildID string, cl *clients.Clients) ([]NominatedActivity, error) {
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	// This query requires an index which is created in terraform
+	query := activityCollection.Select("name", "nominations", "tags").WhereEntity(&firestore.PropertyFilter{
+		Path:     "nominations_count",
+		Operator: ">",
+		Value:    0,
+	}).WhereEntity(&firestore.PropertyFilter{
+		Path:     "guild_id",
+		Operator: "==",
+		Value:    guildID,
+	}).OrderBy("nominations_count", firestore.Desc).OrderBy("random.num_1", firestore.Asc)
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	results := make([]NominatedActivity, 0)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iter.Next: %v", err)
+		}
+		var inAct InnerActivity
+		err = doc.DataTo(&inAct)
+		if err != nil {
+			return nil, fmt.Errorf("doc.DataTo: %v", err)
+		}
+		results = append(results, NominatedActivity{
+			Name:        inAct.Name,
+			Nominations: inAct.Nominations,
+			Tags:        inAct.Tags,
+		})
+	}
+	return results, nil
+}
+
 func ClearNominations(ctx context.Context, guildID string, cl *clients.Clients) error {
 	firestoreClient, err := cl.Firestore()
 	if err != nil {
@@ -550,6 +1608,15 @@ func RecoverActivity(ctx context.Context, guildID, partialName string, cl *clien
 	} else {
 		return act.inner.Name, nil
 	}
+	act, err = GetActivityByAlias(ctx, partialName, guildID, cl)
+	if err != nil {
+		ae, ok := err.(*ActivityError)
+		if !ok || ae.Reason != DOES_NOT_EXIST {
+			return "", fmt.Errorf("getActivityByAlias: %v", err)
+		}
+	} else {
+		return act.inner.Name, nil
+	}
 	// Search for partial match
 	// Use search name because I don't want another index
 	lowerName := strings.ToLower(partialName)
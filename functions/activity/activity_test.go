@@ -0,0 +1,83 @@
+package activity
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"abc", "abc", 0},
+		{"kitten", "sitting", 3},
+		{"ab", "ba", 1},
+		{"minecraft", "minecarft", 1},
+		{"héllo", "hello", 1},
+		{"ca", "abc", 3},
+	}
+	for _, test := range tests {
+		if got := editDistance(test.a, test.b); got != test.want {
+			t.Errorf(`editDistance(%q, %q) = %v, want %v`, test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestMaxNameEdits(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"", 0},
+		{"lol", 0},
+		{"halo", 1},
+		{"minecraft", 1},
+		{"portal two", 2},
+		{"the legend of zelda", 3},
+		{strings.Repeat("a", 30), 3},
+	}
+	for _, test := range tests {
+		if got := maxNameEdits(test.name); got != test.want {
+			t.Errorf(`maxNameEdits(%q) = %v, want %v`, test.name, got, test.want)
+		}
+	}
+}
+
+func TestNormalizeAliases(t *testing.T) {
+	tests := []struct {
+		raw         string
+		want        []string
+		wantTooLong []string
+	}{
+		{"", []string{}, []string{}},
+		{" MC , mc,Mine  Craft", []string{"mc", "mine craft"}, []string{}},
+		{"botw, " + strings.Repeat("a", 40), []string{"botw"}, []string{strings.Repeat("a", 40)}},
+	}
+	for _, test := range tests {
+		got, tooLong := NormalizeAliases(test.raw)
+		if !slices.Equal(got, test.want) || !slices.Equal(tooLong, test.wantTooLong) {
+			t.Errorf(`NormalizeAliases(%q) = %v, %v, want %v, %v`, test.raw, got, tooLong, test.want, test.wantTooLong)
+		}
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{"", []string{}},
+		{" Co-op, RPG ,co-op,, ", []string{"co-op", "rpg"}},
+		{"Horror, horror ", []string{"horror"}},
+		{strings.Repeat("a", 40), []string{strings.Repeat("a", MAX_TAG_LENGTH)}},
+	}
+	for _, test := range tests {
+		if got := NormalizeTags(test.raw); !slices.Equal(got, test.want) {
+			t.Errorf(`NormalizeTags(%q) = %v, want %v`, test.raw, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package activity
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"cloud.google.com/go/firestore"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"google.golang.org/api/iterator"
+)
+
+// How long a guild's pool names are reused before being reloaded
+const SEARCH_INDEX_TTL time.Duration = time.Second * 30
+
+// Most guilds whose pool names are held in memory at once
+const MAX_SEARCH_INDEX_GUILDS int = 100
+
+// Time allowed to load a guild's pool names. Discord drops autocompletes after 3 seconds
+const SEARCH_INDEX_LOAD_TIMEOUT time.Duration = time.Millisecond * 1500
+
+type indexEntry struct {
+	name        string
+	searchName  string
+	words       []string
+	aliases     []string
+	nominations int
+}
+
+type guildIndex struct {
+	loadedAt time.Time
+	entries  []indexEntry
+}
+
+// searchIndex holds the pool names of recently searched guilds in memory.
+// Instances are reused between requests so the pool isn't read on every keystroke.
+// Pool changes are made by other function instances so they show up once the names expire after SEARCH_INDEX_TTL.
+var searchIndex = struct {
+	sync.Mutex
+	guilds map[string]*guildIndex
+}{
+	guilds: make(map[string]*guildIndex),
+}
+
+// storeSearchIndex keeps the guild's pool names, dropping expired guilds and then the oldest when full
+func storeSearchIndex(guildID string, index *guildIndex) {
+	searchIndex.Lock()
+	defer searchIndex.Unlock()
+	if _, ok := searchIndex.guilds[guildID]; !ok && len(searchIndex.guilds) >= MAX_SEARCH_INDEX_GUILDS {
+		oldestID := ""
+		for id, other := range searchIndex.guilds {
+			if time.Since(other.loadedAt) >= SEARCH_INDEX_TTL {
+				delete(searchIndex.guilds, id)
+				continue
+			}
+			if oldestID == "" || other.loadedAt.Before(searchIndex.guilds[oldestID].loadedAt) {
+				oldestID = id
+			}
+		}
+		if len(searchIndex.guilds) >= MAX_SEARCH_INDEX_GUILDS {
+			delete(searchIndex.guilds, oldestID)
+		}
+	}
+	searchIndex.guilds[guildID] = index
+}
+
+func getSearchIndex(ctx context.Context, guildID string, cl *clients.Clients) (*guildIndex, error) {
+	searchIndex.Lock()
+	index, ok := searchIndex.guilds[guildID]
+	searchIndex.Unlock()
+	if ok && time.Since(index.loadedAt) < SEARCH_INDEX_TTL {
+		return index, nil
+	}
+
+	activityCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, SEARCH_INDEX_LOAD_TIMEOUT)
+	defer cancel()
+	query := activityCollection.Select("name", "search_name", "aliases", "nominations_count").WhereEntity(&firestore.PropertyFilter{
+		Path:     "guild_id",
+		Operator: "==",
+		Value:    guildID,
+	})
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	index = &guildIndex{
+		loadedAt: time.Now(),
+		entries:  make([]indexEntry, 0),
+	}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iter.Next: %v", err)
+		}
+		var inAct InnerActivity
+		err = doc.DataTo(&inAct)
+		if err != nil {
+			return nil, fmt.Errorf("doc.DataTo: %v", err)
+		}
+		searchName := SearchName(inAct.SearchName)
+		index.entries = append(index.entries, indexEntry{
+			name:        inAct.Name,
+			searchName:  searchName,
+			words:       strings.FieldsFunc(searchName, isWordSeparator),
+			aliases:     inAct.Aliases,
+			nominations: inAct.NominationsCount,
+		})
+	}
+	storeSearchIndex(guildID, index)
+	return index, nil
+}
+
+func isWordSeparator(r rune) bool {
+	return strings.ContainsRune(" -_:.,'/&()", r)
+}
+
+// matchScore returns how well the query matches the entry. 0 means it doesn't match.
+// Every query word has to match a word of the name, from the start, inside or with a typo.
+func matchScore(entry indexEntry, query string, queryWords []string) int {
+	// Aliases are short so only a full or starting match counts
+	for _, alias := range entry.aliases {
+		if alias == query {
+			return 10
+		}
+		if strings.HasPrefix(alias, query) {
+			return 8
+		}
+	}
+	score := 0
+	if strings.HasPrefix(entry.searchName, query) {
+		score += 4
+	}
+	for _, queryWord := range queryWords {
+		best := 0
+		for _, word := range entry.words {
+			switch {
+			case strings.HasPrefix(word, queryWord):
+				best = max(best, 3)
+			case strings.Contains(word, queryWord):
+				best = max(best, 2)
+			case fuzzyPrefix(word, queryWord):
+				best = max(best, 1)
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		score += best
+	}
+	return score
+}
+
+// fuzzyPrefix returns whether the start of word is a few typos away from queryWord
+func fuzzyPrefix(word, queryWord string) bool {
+	maxEdits := maxNameEdits(queryWord)
+	if maxEdits == 0 {
+		return false
+	}
+	runes := []rune(word)
+	length := utf8.RuneCountInString(queryWord)
+	// Typos can add or drop letters so the matching start can be shorter or longer
+	for n := max(length-maxEdits, 1); n <= min(length+maxEdits, len(runes)); n++ {
+		if editDistance(string(runes[:n]), queryWord) <= maxEdits {
+			return true
+		}
+	}
+	return false
+}
+
+// AutocompleteActivities returns the pool names matching any of their words, ranked by match and nominations
+func AutocompleteActivities(ctx context.Context, guildID, text string, cl *clients.Clients) ([]*discordgo.ApplicationCommandOptionChoice, error) {
+	index, err := getSearchIndex(ctx, guildID, cl)
+	if err != nil {
+		// Prefix matches are better than none
+		ctxzap.Warn(ctx, fmt.Sprintf("getSearchIndex: %v", err))
+		return autocompletePrefix(ctx, guildID, text, cl)
+	}
+	query := SearchName(text)
+	queryWords := strings.FieldsFunc(query, isWordSeparator)
+	if len(queryWords) == 0 {
+		return []*discordgo.ApplicationCommandOptionChoice{}, nil
+	}
+
+	type match struct {
+		entry indexEntry
+		score int
+	}
+	matches := make([]match, 0)
+	for _, entry := range index.entries {
+		if score := matchScore(entry, query, queryWords); score > 0 {
+			matches = append(matches, match{entry, score})
+		}
+	}
+	slices.SortFunc(matches, func(a, b match) int {
+		return cmp.Or(
+			cmp.Compare(b.score, a.score),
+			cmp.Compare(b.entry.nominations, a.entry.nominations),
+			cmp.Compare(a.entry.searchName, b.entry.searchName),
+		)
+	})
+
+	results := make([]*discordgo.ApplicationCommandOptionChoice, 0, utils.MAX_AUTOCOMPLETE_ENTRIES)
+	for _, m := range matches[:min(len(matches), utils.MAX_AUTOCOMPLETE_ENTRIES)] {
+		results = append(results, &discordgo.ApplicationCommandOptionChoice{
+			Name:  m.entry.name,
+			Value: m.entry.name,
+		})
+	}
+	return results, nil
+}
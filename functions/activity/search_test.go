@@ -0,0 +1,60 @@
+package activity
+
+import (
+	"strings"
+	"testing"
+)
+
+func newIndexEntry(name string, aliases ...string) indexEntry {
+	searchName := SearchName(name)
+	return indexEntry{
+		name:       name,
+		searchName: searchName,
+		words:      strings.FieldsFunc(searchName, isWordSeparator),
+		aliases:    aliases,
+	}
+}
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		entry indexEntry
+		text  string
+		want  int
+	}{
+		{newIndexEntry("Minecraft", "mc"), "MC", 10},
+		{newIndexEntry("Breath of the Wild", "botw"), "bot", 8},
+		{newIndexEntry("Minecraft"), "mine", 7},
+		{newIndexEntry("Rocket League"), "league", 3},
+		{newIndexEntry("Rocket League"), "eag", 2},
+		{newIndexEntry("Rocket League"), "league rocket", 6},
+		{newIndexEntry("Minecraft"), "mniecraft", 1},
+		{newIndexEntry("Rocket League"), "rocket soccer", 0},
+		{newIndexEntry("Portal 2"), "xyz", 0},
+	}
+	for _, test := range tests {
+		query := SearchName(test.text)
+		if got := matchScore(test.entry, query, strings.FieldsFunc(query, isWordSeparator)); got != test.want {
+			t.Errorf(`matchScore(%v, %q) = %v, want %v`, test.entry.name, test.text, got, test.want)
+		}
+	}
+}
+
+func TestFuzzyPrefix(t *testing.T) {
+	tests := []struct {
+		word, queryWord string
+		want            bool
+	}{
+		{"minecraft", "mine", true},
+		{"minecraft", "mnie", true},
+		{"zelda", "zeldaa", true},
+		{"minecraft", "lol", false},
+		{"halo", "hola", false},
+		{"fortnite", "apex", false},
+		{"ab", "abcd", false},
+	}
+	for _, test := range tests {
+		if got := fuzzyPrefix(test.word, test.queryWord); got != test.want {
+			t.Errorf(`fuzzyPrefix(%q, %q) = %v, want %v`, test.word, test.queryWord, got, test.want)
+		}
+	}
+}
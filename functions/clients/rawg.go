@@ -39,6 +39,34 @@ func (r *Rawg) SearchGame(ctx context.Context, name string, page, pageSize int)
 	return r.client.GetGames(ctx, rawg.NewGamesFilter().SetPageSize(pageSize).SetPage(page+1).SetStores(STEAM_STORE, GOG_STORE, EPIC_GAMES).SetSearch(name))
 }
 
+func (r *Rawg) GetSuggestedGames(ctx context.Context, id int) ([]*rawg.Game, error) {
+	ctxzap.Info(ctx, "RAWG: Getting suggested games", zap.Int("id", id), zap.String("type", "GetGameSuggested"))
+	games, _, err := r.client.GetGameSuggested(ctx, id)
+	return games, err
+}
+
+// GetGamesLike returns the highest rated games with any of the genres or tags
+func (r *Rawg) GetGamesLike(ctx context.Context, genres, tags []string, pageSize int) ([]*rawg.Game, error) {
+	ctxzap.Info(ctx, "RAWG: Getting similar games", zap.Strings("genres", genres), zap.Strings("tags", tags), zap.String("type", "GetGames"))
+	filter := rawg.NewGamesFilter().SetPageSize(pageSize).SetStores(STEAM_STORE, GOG_STORE, EPIC_GAMES).SetOrdering("-rating")
+	if len(genres) > 0 {
+		filter.SetGenres(toInterfaces(genres)...)
+	}
+	if len(tags) > 0 {
+		filter.SetTags(toInterfaces(tags)...)
+	}
+	games, _, err := r.client.GetGames(ctx, filter)
+	return games, err
+}
+
+func toInterfaces(values []string) []interface{} {
+	results := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		results = append(results, v)
+	}
+	return results
+}
+
 func (r *Rawg) AutocompleteGames(ctx context.Context, guildID, text string, entries int) ([]*discordgo.ApplicationCommandOptionChoice, error) {
 	gamesList, _, err := r.SearchGame(ctx, text, 0, entries)
 	if err != nil {
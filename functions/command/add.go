@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
 	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/customid"
 	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
 	"github.com/bwmarrin/discordgo"
 	"github.com/dimuska139/rawg-sdk-go/v3"
@@ -19,7 +22,7 @@ func validateName(name string) (bool, string) {
 		return false, "Reserved name"
 	}
 	// Check length. 55 is the max poll entry
-	if len(name) > 55 {
+	if utf8.RuneCountInString(name) > MAX_ANSWER_LENGTH {
 		return false, "Name too long"
 	}
 	return true, ""
@@ -27,27 +30,37 @@ func validateName(name string) (bool, string) {
 
 type AddCommand struct {
 	GuildID      string
+	UserID       string
 	ActivityType string
 	Name         string
+	// Add even if similar activities are in the pool
+	Confirmed bool
 }
 
-func NewAddCommand(guildID, activityType, name string) *AddCommand {
+func NewAddCommand(guildID, userID, activityType, name string, confirmed bool) *AddCommand {
 	return &AddCommand{
 		GuildID:      guildID,
+		UserID:       userID,
 		ActivityType: activityType,
 		Name:         name,
+		Confirmed:    confirmed,
 	}
 }
 
 func (c *AddCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
 	var typ activity.ActivityType
 	var info *activity.GameInfo
-	name := c.Name
+	var tags []string
+	var minPlayers, maxPlayers int
+	name := activity.NormalizeName(c.Name)
 	switch c.ActivityType {
 	case "activity":
 		typ = activity.ACTIVITY
 		valid, reason := validateName(name)
-		if !valid {
+		if !valid || name == "" {
+			if name == "" {
+				reason = "Empty name"
+			}
 			return utils.NewWebhookEdit(fmt.Sprintf("Invalid activity: %v", reason)), nil
 		}
 	case "game":
@@ -71,18 +84,80 @@ func (c *AddCommand) Execute(ctx context.Context, cl *clients.Clients) (*discord
 			Slug:            detail.Slug,
 			BackgroundImage: detail.ImageBackground,
 		}
+		// Seed the tags with the game's genres
+		genres := make([]string, 0, len(detail.Genres))
+		for _, genre := range detail.Genres {
+			genres = append(genres, genre.Name)
+		}
+		tags = activity.NormalizeTags(strings.Join(genres, ","))
+		minPlayers, maxPlayers = rawgPlayerCount(detail)
 	default:
 		return nil, fmt.Errorf("activity type not supported: %v", c.ActivityType)
 	}
-	_, err := activity.Create(ctx, typ, name, c.GuildID, info, cl)
+	existing, err := activity.FindExisting(ctx, c.GuildID, name, info, cl)
+	if err != nil {
+		return nil, fmt.Errorf("findExisting: %v", err)
+	}
+	if existing != "" {
+		return c.reply(fmt.Sprintf("%v already exists in the pool as %v", name, existing)), nil
+	}
+	if !c.Confirmed {
+		similar, err := activity.FindSimilar(ctx, c.GuildID, name, cl)
+		if err != nil {
+			return nil, fmt.Errorf("findSimilar: %v", err)
+		}
+		if len(similar) > 0 {
+			return c.confirmDuplicate(ctx, name, similar, cl)
+		}
+	}
+	_, err = activity.Create(ctx, typ, name, c.GuildID, info, tags, minPlayers, maxPlayers, c.UserID, cl)
 	if err != nil {
 		ae, ok := err.(*activity.ActivityError)
 		if ok {
 			if ae.Reason == activity.ALREADY_EXISTS {
-				return utils.NewWebhookEdit(fmt.Sprintf("%v already exists in the pool", c.Name)), nil
+				return c.reply(fmt.Sprintf("%v already exists in the pool", name)), nil
 			}
 		}
 		return nil, fmt.Errorf("act.Create: %v", err)
 	}
-	return utils.NewWebhookEdit(fmt.Sprintf("%v added to the pool", name)), nil
+	return c.reply(fmt.Sprintf("%v added to the pool", name)), nil
+}
+
+// confirmDuplicate asks whether to add the activity although similar ones are in the pool
+func (c *AddCommand) confirmDuplicate(ctx context.Context, name string, similar []string, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	customID, err := customid.CreateCustomID(ctx, "add-confirm", customid.Filter{
+		Name: c.Name,
+		Type: c.ActivityType,
+	}, 0, cl)
+	if err != nil {
+		return nil, fmt.Errorf("CreateCustomID: %v", err)
+	}
+	discordCustomID, err := customID.ToDiscordCustomID()
+	if err != nil {
+		return nil, fmt.Errorf("ToDiscordCustomID: %v", err)
+	}
+	content := fmt.Sprintf("%v looks like %v already in the pool. Add it anyway?", name, strings.Join(similar, ", "))
+	return &discordgo.WebhookEdit{
+		Content: &content,
+		Components: &[]discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					discordgo.Button{
+						Label:    "Add anyway",
+						Style:    discordgo.PrimaryButton,
+						CustomID: discordCustomID,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// reply removes the confirmation button when answering it
+func (c *AddCommand) reply(content string) *discordgo.WebhookEdit {
+	edit := utils.NewWebhookEdit(content)
+	if c.Confirmed {
+		edit.Components = &[]discordgo.MessageComponent{}
+	}
+	return edit
 }
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Max number of aliases on a single activity
+const MAX_ALIASES int = 10
+
+type AliasCommand struct {
+	GuildID string
+	Name    string
+	Aliases string
+	Remove  bool
+}
+
+func NewAliasCommand(guildID, name, aliases string, remove bool) *AliasCommand {
+	return &AliasCommand{
+		GuildID: guildID,
+		Name:    name,
+		Aliases: aliases,
+		Remove:  remove,
+	}
+}
+
+func (c *AliasCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	aliases, tooLong := activity.NormalizeAliases(c.Aliases)
+	if len(tooLong) > 0 {
+		return utils.NewWebhookEdit(fmt.Sprintf("Aliases can be at most %v characters: %v", activity.MAX_ALIAS_LENGTH, strings.Join(tooLong, ", "))), nil
+	}
+	if len(aliases) == 0 {
+		return utils.NewWebhookEdit("No aliases were given"), nil
+	}
+	act, err := activity.GetActivity(ctx, c.Name, c.GuildID, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			return utils.NewWebhookEdit(fmt.Sprintf("%v does not exist", c.Name)), nil
+		}
+		return nil, fmt.Errorf("getActivity: %v", err)
+	}
+	if c.Remove {
+		err = act.RemoveAliases(ctx, aliases)
+		if err != nil {
+			return nil, fmt.Errorf("removeAliases: %v", err)
+		}
+	} else {
+		added := slices.DeleteFunc(slices.Clone(aliases), func(alias string) bool {
+			return slices.Contains(act.Aliases(), alias)
+		})
+		if len(act.Aliases())+len(added) > MAX_ALIASES {
+			return utils.NewWebhookEdit(fmt.Sprintf("An activity can have at most %v aliases", MAX_ALIASES)), nil
+		}
+		err = act.AddAliases(ctx, aliases, cl)
+		if err != nil {
+			ae, ok := err.(*activity.ActivityError)
+			if ok && ae.Reason == activity.ALIAS_TAKEN {
+				return utils.NewWebhookEdit("Aliases must be unique. Another game/activity is already named or known by one of them"), nil
+			}
+			return nil, fmt.Errorf("addAliases: %v", err)
+		}
+	}
+	if len(act.Aliases()) == 0 {
+		return utils.NewWebhookEdit(fmt.Sprintf("%v has no aliases", act.Name())), nil
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("%v aliases: %v", act.Name(), strings.Join(act.Aliases(), ", "))), nil
+}
@@ -0,0 +1,217 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotchance/orderedmap/v2"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// Length in hours of each tournament match
+const MATCH_DURATION int = 2
+
+const MATCH_EMOJI = "⚔️"
+
+// Seeds are taken from nominations first and the rest are random
+var bracketComposition = []guild.CompositionSlot{
+	{Provider: NOMINATIONS_PROVIDER, Weight: 1},
+}
+
+type StartTournamentCommand struct {
+	GuildID string
+	Size    int
+}
+
+func NewStartTournamentCommand(guildID string, size int) *StartTournamentCommand {
+	return &StartTournamentCommand{
+		GuildID: guildID,
+		Size:    size,
+	}
+}
+
+func (c *StartTournamentCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	if c.Size != 8 && c.Size != 16 {
+		return utils.NewWebhookEdit("A tournament must have 8 or 16 entries"), nil
+	}
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	pollID, err := g.GetActivePoll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getActivePoll: %v", err)
+	}
+	pollID, err = closeTimePoll(ctx, c.GuildID, pollID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("closeTimePoll: %v", err)
+	}
+	if pollID != nil {
+		return utils.NewWebhookEdit("There is already an active poll"), nil
+	}
+	poolSize, err := activity.GetPoolSize(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getPoolSize: %v", err)
+	}
+	if poolSize < int64(c.Size) {
+		return utils.NewWebhookEdit(fmt.Sprintf("The pool needs at least %v entries for this tournament", c.Size)), nil
+	}
+
+	answers := orderedmap.NewOrderedMap[string, answerEntry]()
+	err = composePoll(ctx, g, bracketComposition, c.Size, answers, cl)
+	if err != nil {
+		return nil, fmt.Errorf("composePoll: %v", err)
+	}
+	seeds := answers.Keys()
+	if len(seeds) < c.Size {
+		return utils.NewWebhookEdit("Not enough entries could be found for the tournament"), nil
+	}
+
+	seedNumbers := make(map[string]int, len(seeds))
+	for i, seed := range seeds {
+		seedNumbers[seed] = i + 1
+	}
+	bracket := &guild.BracketInfo{
+		Round:     1,
+		Remaining: seedBracket(seeds),
+		Advancing: []string{},
+		Seeds:     seedNumbers,
+	}
+	err = g.SetBracket(ctx, bracket)
+	if err != nil {
+		return nil, fmt.Errorf("setBracket: %v", err)
+	}
+	ctxzap.Info(ctx, fmt.Sprintf("Started tournament: %v", bracket.Remaining))
+	return startMatch(ctx, bracket, c.GuildID, cl)
+}
+
+// seedBracket orders seeds so that pairs play each other and the top seeds meet last
+func seedBracket(seeds []string) []string {
+	order := []int{0}
+	for len(order) < len(seeds) {
+		next := make([]int, 0, len(order)*2)
+		for _, seed := range order {
+			next = append(next, seed, len(order)*2-1-seed)
+		}
+		order = next
+	}
+	results := make([]string, 0, len(seeds))
+	for _, seed := range order {
+		results = append(results, seeds[seed])
+	}
+	return results
+}
+
+// matchWinner returns the entrant of the match with the most votes. Ties go to the higher seed
+func matchWinner(match []string, winners []string, seeds map[string]int) string {
+	contenders := slices.DeleteFunc(slices.Clone(match), func(name string) bool {
+		return !slices.Contains(winners, name)
+	})
+	if len(contenders) == 0 {
+		contenders = slices.Clone(match)
+	}
+	return slices.MinFunc(contenders, func(a, b string) int {
+		return seedOf(a, seeds) - seedOf(b, seeds)
+	})
+}
+
+// seedOf returns the activity's seed. Tournaments started before seeds were kept rank entrants by position
+func seedOf(name string, seeds map[string]int) int {
+	if seed, ok := seeds[name]; ok {
+		return seed
+	}
+	return math.MaxInt
+}
+
+func startMatch(ctx context.Context, bracket *guild.BracketInfo, guildID string, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	entries := []guild.PollEntry{
+		{Name: bracket.Remaining[bracket.Match*2], Emoji: MATCH_EMOJI},
+		{Name: bracket.Remaining[bracket.Match*2+1], Emoji: MATCH_EMOJI},
+	}
+	pollCmd := NewCreatePollCommand(guildID, entries, MATCH_DURATION, false)
+	pollCmd.SkipActivePollCheck(true)
+	pollCmd.SetBracketRound(bracket.Round)
+	return pollCmd.Execute(ctx, cl)
+}
+
+// advanceBracket records the winner of a match and starts the next one
+func advanceBracket(ctx context.Context, s *discordgo.Session, g *guild.Guild, pollInfo *guild.PollInfo, result *pollResult, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	bracket, err := g.GetBracket(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getBracket: %v", err)
+	}
+	if bracket == nil {
+		// The tournament is gone so there is nothing to advance
+		err = g.ClearActivePoll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("clearActivePoll: %v", err)
+		}
+		return utils.NewWebhookEdit("Match ended but there is no tournament running"), nil
+	}
+	winner := matchWinner(bracket.Remaining[bracket.Match*2:bracket.Match*2+2], result.winners, bracket.Seeds)
+	bracket.Advancing = append(bracket.Advancing, winner)
+	bracket.Match++
+
+	description := fmt.Sprintf("%v advances", winner)
+	if result.tie {
+		description = fmt.Sprintf("The match was tied. %v advances as the higher seed", winner)
+	}
+	roundOver := bracket.Match*2 >= len(bracket.Remaining)
+	if roundOver && len(bracket.Advancing) == 1 {
+		description = fmt.Sprintf("🏆 %v is the champion and the new flavor of the week", winner)
+	}
+	_, err = s.ChannelMessageSendComplex(pollInfo.ChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       fmt.Sprintf("Tournament round %v", bracket.Round),
+				Description: description,
+				Color:       2326507,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("channelMessageSendComplex: %v", err)
+	}
+
+	if roundOver {
+		if len(bracket.Advancing) == 1 {
+			err = declareWinner(ctx, winner, g.GetGuildId(), g, cl)
+			if err != nil {
+				return nil, fmt.Errorf("declareWinner: %v", err)
+			}
+			err = g.ClearBracket(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("clearBracket: %v", err)
+			}
+			err = g.ClearActivePoll(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("clearActivePoll: %v", err)
+			}
+			err = activity.ClearNominations(ctx, g.GetGuildId(), cl)
+			if err != nil {
+				return nil, fmt.Errorf("clearNominations: %v", err)
+			}
+			err = startTimePoll(ctx, g, winner, cl)
+			if err != nil {
+				return nil, fmt.Errorf("startTimePoll: %v", err)
+			}
+			return utils.NewWebhookEdit(fmt.Sprintf("Tournament ended\nChampion: %v", winner)), nil
+		}
+		bracket.Round++
+		bracket.Remaining = bracket.Advancing
+		bracket.Advancing = []string{}
+		bracket.Match = 0
+	}
+	err = g.SetBracket(ctx, bracket)
+	if err != nil {
+		return nil, fmt.Errorf("setBracket: %v", err)
+	}
+	return startMatch(ctx, bracket, g.GetGuildId(), cl)
+}
@@ -0,0 +1,42 @@
+package command
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSeedBracket(t *testing.T) {
+	tests := []struct {
+		seeds []string
+		want  []string
+	}{
+		{[]string{"1", "2"}, []string{"1", "2"}},
+		{[]string{"1", "2", "3", "4"}, []string{"1", "4", "2", "3"}},
+		{[]string{"1", "2", "3", "4", "5", "6", "7", "8"}, []string{"1", "8", "4", "5", "2", "7", "3", "6"}},
+	}
+	for _, test := range tests {
+		if got := seedBracket(test.seeds); !slices.Equal(got, test.want) {
+			t.Errorf(`seedBracket(%v) = %v, want %v`, test.seeds, got, test.want)
+		}
+	}
+}
+
+func TestMatchWinner(t *testing.T) {
+	tests := []struct {
+		match   []string
+		winners []string
+		seeds   map[string]int
+		want    string
+	}{
+		{[]string{"a", "b"}, []string{"b"}, map[string]int{"a": 1, "b": 2}, "b"},
+		{[]string{"a", "b"}, []string{"a", "b"}, map[string]int{"a": 3, "b": 2}, "b"},
+		{[]string{"a", "b"}, []string{}, map[string]int{"a": 5, "b": 4}, "b"},
+		{[]string{"a", "b"}, []string{"c"}, map[string]int{"a": 2, "b": 1}, "b"},
+		{[]string{"a", "b"}, []string{"a", "b"}, nil, "a"},
+	}
+	for _, test := range tests {
+		if got := matchWinner(test.match, test.winners, test.seeds); got != test.want {
+			t.Errorf(`matchWinner(%v, %v, %v) = %v, want %v`, test.match, test.winners, test.seeds, got, test.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
 	"github.com/PinkNoize/flavor-of-the-week/functions/customid"
@@ -74,6 +75,16 @@ func (c *DiscordCommand) LogCommand(ctx context.Context) {
 			zap.String("custom_id", data.CustomID),
 			zap.String("guildID", c.interaction.GuildID),
 		)
+	case discordgo.InteractionModalSubmit:
+		data := c.interaction.ModalSubmitData()
+
+		ctxzap.Info(ctx, fmt.Sprintf("User %v (%v) submitted a modal", c.UserNick(), c.UserID()),
+			zap.String("type", "audit"),
+			zap.String("nick", c.UserNick()),
+			zap.String("userid", c.UserID()),
+			zap.String("custom_id", data.CustomID),
+			zap.String("guildID", c.interaction.GuildID),
+		)
 	}
 }
 
@@ -99,6 +110,11 @@ func (c *DiscordCommand) UserID() string {
 	}
 }
 
+// IsAdmin returns whether the user has the administrator permission in the guild
+func (c *DiscordCommand) IsAdmin() bool {
+	return c.interaction.Member != nil && c.interaction.Member.Permissions&discordgo.PermissionAdministrator != 0
+}
+
 func (c *DiscordCommand) UserNick() string {
 	if c.interaction.Member != nil {
 		name := c.interaction.Member.Nick
@@ -133,10 +149,25 @@ func (c *DiscordCommand) ToCommand(ctx context.Context, cl *clients.Clients) (Co
 		return c.fromApplicationCommand()
 	case discordgo.InteractionMessageComponent:
 		return c.fromMessageComponent(ctx, cl)
+	case discordgo.InteractionModalSubmit:
+		return c.fromModalSubmit(ctx, cl)
 	}
 	return nil, fmt.Errorf("unexpected interaction type: %v", c.Type())
 }
 
+// ModalResponse returns the modal to open for the interaction. Returns nil if it doesn't open one.
+func ModalResponse(ctx context.Context, cmd *DiscordCommand, cl *clients.Clients) (*discordgo.InteractionResponse, error) {
+	switch cmd.Type() {
+	case discordgo.InteractionMessageComponent:
+		return ratingModal(cmd.interaction.MessageComponentData()), nil
+	case discordgo.InteractionApplicationCommand:
+		if cmd.CommandName() == "edit" {
+			return editModal(ctx, &cmd.interaction, cl)
+		}
+	}
+	return nil, nil
+}
+
 func (c *DiscordCommand) fromApplicationCommand() (Command, error) {
 	if c.Type() != discordgo.InteractionApplicationCommand {
 		return nil, fmt.Errorf("not a valid command")
@@ -148,12 +179,12 @@ func (c *DiscordCommand) fromApplicationCommand() (Command, error) {
 		if pass, missing := utils.VerifyOpts(args, []string{"type", "name"}); !pass {
 			return nil, fmt.Errorf("missing options: %v", missing)
 		}
-		return NewAddCommand(c.interaction.GuildID, args["type"].StringValue(), args["name"].StringValue()), nil
+		return NewAddCommand(c.interaction.GuildID, c.UserID(), args["type"].StringValue(), args["name"].StringValue(), false), nil
 	case "remove":
 		if pass, missing := utils.VerifyOpts(args, []string{"name"}); !pass {
 			return nil, fmt.Errorf("missing options: %v", missing)
 		}
-		return NewRemoveCommand(c.interaction.GuildID, args["name"].StringValue(), false), nil
+		return NewRemoveCommand(c.interaction.GuildID, c.UserID(), args["name"].StringValue(), c.IsAdmin()), nil
 	case "nominations":
 		subcmd := commandData.Options[0]
 		subcmd_args := utils.OptionsToMap(subcmd.Options)
@@ -202,7 +233,162 @@ func (c *DiscordCommand) fromApplicationCommand() (Command, error) {
 		if ok {
 			actType = actTypeOpt.StringValue()
 		}
-		return NewPoolListCommand(c.interaction.GuildID, name, actType), nil
+		var tag string
+		tagOpt, ok := args["tag"]
+		if ok {
+			tag = tagOpt.StringValue()
+		}
+		var sort string
+		sortOpt, ok := args["sort"]
+		if ok {
+			sort = sortOpt.StringValue()
+		}
+		return NewPoolListCommand(c.interaction.GuildID, name, actType, tag, sort), nil
+	case "own":
+		subcmd := commandData.Options[0]
+		subcmdArgs := utils.OptionsToMap(subcmd.Options)
+		switch subcmd.Name {
+		case "add", "remove":
+			if pass, missing := utils.VerifyOpts(subcmdArgs, []string{"name"}); !pass {
+				return nil, fmt.Errorf("missing options: %v", missing)
+			}
+			return NewOwnCommand(c.interaction.GuildID, c.UserID(), subcmdArgs["name"].StringValue(), subcmd.Name == "remove"), nil
+		case "list":
+			return NewOwnListCommand(c.interaction.GuildID, c.UserID()), nil
+		default:
+			return nil, fmt.Errorf("not a valid command: %v", subcmd.Name)
+		}
+	case "players":
+		if pass, missing := utils.VerifyOpts(args, []string{"name", "min"}); !pass {
+			return nil, fmt.Errorf("missing options: %v", missing)
+		}
+		var maxPlayers int
+		if opt, ok := args["max"]; ok {
+			maxPlayers = int(opt.IntValue())
+		}
+		return NewPlayerCountCommand(c.interaction.GuildID, args["name"].StringValue(), int(args["min"].IntValue()), maxPlayers), nil
+	case "tag":
+		subcmd := commandData.Options[0]
+		subcmdArgs := utils.OptionsToMap(subcmd.Options)
+		if pass, missing := utils.VerifyOpts(subcmdArgs, []string{"name", "tags"}); !pass {
+			return nil, fmt.Errorf("missing options: %v", missing)
+		}
+		switch subcmd.Name {
+		case "add":
+			return NewTagCommand(c.interaction.GuildID, subcmdArgs["name"].StringValue(), subcmdArgs["tags"].StringValue(), false), nil
+		case "remove":
+			return NewTagCommand(c.interaction.GuildID, subcmdArgs["name"].StringValue(), subcmdArgs["tags"].StringValue(), true), nil
+		default:
+			return nil, fmt.Errorf("not a valid command: %v", subcmd.Name)
+		}
+	case "alias":
+		subcmd := commandData.Options[0]
+		subcmdArgs := utils.OptionsToMap(subcmd.Options)
+		if pass, missing := utils.VerifyOpts(subcmdArgs, []string{"name", "aliases"}); !pass {
+			return nil, fmt.Errorf("missing options: %v", missing)
+		}
+		switch subcmd.Name {
+		case "add":
+			return NewAliasCommand(c.interaction.GuildID, subcmdArgs["name"].StringValue(), subcmdArgs["aliases"].StringValue(), false), nil
+		case "remove":
+			return NewAliasCommand(c.interaction.GuildID, subcmdArgs["name"].StringValue(), subcmdArgs["aliases"].StringValue(), true), nil
+		default:
+			return nil, fmt.Errorf("not a valid command: %v", subcmd.Name)
+		}
+	case "my-votes":
+		var tracking *bool
+		trackingOpt, ok := args["tracking"]
+		if ok {
+			enabled := trackingOpt.BoolValue()
+			tracking = &enabled
+		}
+		return NewMyVotesCommand(c.interaction.GuildID, c.UserID(), tracking), nil
+	case "poll":
+		subcmd := commandData.Options[0]
+		switch subcmd.Name {
+		case "preview":
+			return NewPollPreviewCommand(c.interaction.GuildID), nil
+		case "composition":
+			var composition string
+			slotsOpt, ok := utils.OptionsToMap(subcmd.Options)["slots"]
+			if ok {
+				composition = slotsOpt.StringValue()
+			}
+			return NewPollCompositionCommand(c.interaction.GuildID, composition), nil
+		case "ownership":
+			subcmdArgs := utils.OptionsToMap(subcmd.Options)
+			if pass, missing := utils.VerifyOpts(subcmdArgs, []string{"mode"}); !pass {
+				return nil, fmt.Errorf("missing options: %v", missing)
+			}
+			minOwners := 1
+			if opt, ok := subcmdArgs["min-owners"]; ok {
+				minOwners = int(opt.IntValue())
+			}
+			return NewOwnershipCommand(c.interaction.GuildID, subcmdArgs["mode"].StringValue(), minOwners), nil
+		case "players":
+			var players int
+			if opt, ok := utils.OptionsToMap(subcmd.Options)["count"]; ok {
+				players = int(opt.IntValue())
+			}
+			return NewExpectedPlayersCommand(c.interaction.GuildID, players), nil
+		case "themed":
+			subcmdArgs := utils.OptionsToMap(subcmd.Options)
+			if pass, missing := utils.VerifyOpts(subcmdArgs, []string{"tag"}); !pass {
+				return nil, fmt.Errorf("missing options: %v", missing)
+			}
+			return NewThemedPollCommand(c.interaction.GuildID, subcmdArgs["tag"].StringValue()), nil
+		case "fairness":
+			subcmdArgs := utils.OptionsToMap(subcmd.Options)
+			if pass, missing := utils.VerifyOpts(subcmdArgs, []string{"enabled"}); !pass {
+				return nil, fmt.Errorf("missing options: %v", missing)
+			}
+			return NewFairNominationsCommand(c.interaction.GuildID, subcmdArgs["enabled"].BoolValue()), nil
+		case "tournament":
+			subcmdArgs := utils.OptionsToMap(subcmd.Options)
+			if pass, missing := utils.VerifyOpts(subcmdArgs, []string{"size"}); !pass {
+				return nil, fmt.Errorf("missing options: %v", missing)
+			}
+			return NewStartTournamentCommand(c.interaction.GuildID, int(subcmdArgs["size"].IntValue())), nil
+		case "early-close":
+			subcmdArgs := utils.OptionsToMap(subcmd.Options)
+			if pass, missing := utils.VerifyOpts(subcmdArgs, []string{"enabled"}); !pass {
+				return nil, fmt.Errorf("missing options: %v", missing)
+			}
+			var roleID string
+			var voters int
+			if opt, ok := subcmdArgs["role"]; ok {
+				roleID = opt.RoleValue(nil, c.interaction.GuildID).ID
+			}
+			if opt, ok := subcmdArgs["voters"]; ok {
+				voters = int(opt.IntValue())
+			}
+			return NewEarlyCloseCommand(c.interaction.GuildID, subcmdArgs["enabled"].BoolValue(), roleID, voters), nil
+		case "voting":
+			subcmdArgs := utils.OptionsToMap(subcmd.Options)
+			if pass, missing := utils.VerifyOpts(subcmdArgs, []string{"mode"}); !pass {
+				return nil, fmt.Errorf("missing options: %v", missing)
+			}
+			return NewVotingModeCommand(c.interaction.GuildID, subcmdArgs["mode"].StringValue()), nil
+		case "quorum":
+			subcmdArgs := utils.OptionsToMap(subcmd.Options)
+			var minVotes, rolePercent int
+			var roleID, action string
+			if opt, ok := subcmdArgs["min-votes"]; ok {
+				minVotes = int(opt.IntValue())
+			}
+			if opt, ok := subcmdArgs["role"]; ok {
+				roleID = opt.RoleValue(nil, c.interaction.GuildID).ID
+			}
+			if opt, ok := subcmdArgs["percent"]; ok {
+				rolePercent = int(opt.IntValue())
+			}
+			if opt, ok := subcmdArgs["action"]; ok {
+				action = opt.StringValue()
+			}
+			return NewQuorumCommand(c.interaction.GuildID, minVotes, roleID, rolePercent, action), nil
+		default:
+			return nil, fmt.Errorf("not a valid command: %v", subcmd.Name)
+		}
 	case "start-poll":
 		return NewStartPollCommand(c.interaction.GuildID), nil
 	case "end-poll":
@@ -217,6 +403,42 @@ func (c *DiscordCommand) fromApplicationCommand() (Command, error) {
 			return nil, fmt.Errorf("missing options: %v", missing)
 		}
 		return NewSchedulePollCommand(c.interaction.GuildID, args["day"].StringValue(), int(args["hour"].IntValue())), nil
+	case "session":
+		subcmd := commandData.Options[0]
+		subcmdArgs := utils.OptionsToMap(subcmd.Options)
+		switch subcmd.Name {
+		case "set":
+			if pass, missing := utils.VerifyOpts(subcmdArgs, []string{"day", "hour", "channel"}); !pass {
+				return nil, fmt.Errorf("missing options: %v", missing)
+			}
+			var minute, duration int
+			if opt, ok := subcmdArgs["minute"]; ok {
+				minute = int(opt.IntValue())
+			}
+			if opt, ok := subcmdArgs["duration"]; ok {
+				duration = int(opt.IntValue())
+			}
+			return NewSessionSlotCommand(
+				c.interaction.GuildID,
+				subcmdArgs["day"].StringValue(),
+				int(subcmdArgs["hour"].IntValue()),
+				minute,
+				subcmdArgs["channel"].ChannelValue(nil).ID,
+				duration,
+			), nil
+		case "time-poll":
+			var slots string
+			if opt, ok := subcmdArgs["slots"]; ok {
+				slots = opt.StringValue()
+			}
+			return NewTimePollCommand(c.interaction.GuildID, slots), nil
+		case "cancel":
+			return NewCancelSessionCommand(c.interaction.GuildID, false), nil
+		case "clear":
+			return NewCancelSessionCommand(c.interaction.GuildID, true), nil
+		default:
+			return nil, fmt.Errorf("not a valid command: %v", subcmd.Name)
+		}
 	case "override-fow":
 		if pass, missing := utils.VerifyOpts(args, []string{"name"}); !pass {
 			return nil, fmt.Errorf("missing options: %v", missing)
@@ -226,14 +448,60 @@ func (c *DiscordCommand) fromApplicationCommand() (Command, error) {
 		if pass, missing := utils.VerifyOpts(args, []string{"name"}); !pass {
 			return nil, fmt.Errorf("missing options: %v", missing)
 		}
-		return NewRemoveCommand(c.interaction.GuildID, args["name"].StringValue(), true), nil
+		return NewRemoveCommand(c.interaction.GuildID, c.UserID(), args["name"].StringValue(), true), nil
 	case "stats":
 		return NewStatsCommand(c.interaction.GuildID), nil
+	case "roster":
+		return NewRosterCommand(c.interaction.GuildID), nil
+	case "rename":
+		if pass, missing := utils.VerifyOpts(args, []string{"name", "new-name"}); !pass {
+			return nil, fmt.Errorf("missing options: %v", missing)
+		}
+		return NewRenameCommand(c.interaction.GuildID, args["name"].StringValue(), args["new-name"].StringValue()), nil
+	case "activity-info":
+		if pass, missing := utils.VerifyOpts(args, []string{"name"}); !pass {
+			return nil, fmt.Errorf("missing options: %v", missing)
+		}
+		return NewActivityInfoCommand(c.interaction.GuildID, args["name"].StringValue()), nil
+	case "edit":
+		if pass, missing := utils.VerifyOpts(args, []string{"name"}); !pass {
+			return nil, fmt.Errorf("missing options: %v", missing)
+		}
+		var typ, game, emoji string
+		if opt, ok := args["type"]; ok {
+			typ = opt.StringValue()
+		}
+		if opt, ok := args["game"]; ok {
+			game = opt.StringValue()
+		}
+		if opt, ok := args["emoji"]; ok {
+			emoji = strings.TrimSpace(opt.StringValue())
+		}
+		return NewEditActivityCommand(c.interaction.GuildID, args["name"].StringValue(), typ, game, emoji), nil
+	case "teams":
+		if pass, missing := utils.VerifyOpts(args, []string{"count"}); !pass {
+			return nil, fmt.Errorf("missing options: %v", missing)
+		}
+		var channelID string
+		if opt, ok := args["channel"]; ok {
+			channelID = opt.ChannelValue(nil).ID
+		}
+		var goingOnly bool
+		if opt, ok := args["going-only"]; ok {
+			goingOnly = opt.BoolValue()
+		}
+		var avoidRepeats bool
+		if opt, ok := args["avoid-repeats"]; ok {
+			avoidRepeats = opt.BoolValue()
+		}
+		return NewTeamsCommand(c.interaction.GuildID, int(args["count"].IntValue()), channelID, goingOnly, avoidRepeats), nil
 	case "search":
 		if pass, missing := utils.VerifyOpts(args, []string{"name"}); !pass {
 			return nil, fmt.Errorf("missing options: %v", missing)
 		}
 		return NewSearchCommand(args["name"].StringValue(), 0), nil
+	case "recommend":
+		return NewRecommendCommand(c.interaction.GuildID), nil
 	case "help":
 		return NewHelpCommand(), nil
 	default:
@@ -259,21 +527,83 @@ func (c *DiscordCommand) fromMessageComponent(ctx context.Context, cl *clients.C
 			return NewNominationListCommandFromCustomID(c.interaction.GuildID, "", customID), nil
 		case "nominations-mine":
 			return NewNominationListCommandFromCustomID(c.interaction.GuildID, c.interaction.Member.User.ID, customID), nil
+		case "own-list":
+			return NewOwnListCommandFromCustomID(c.interaction.GuildID, c.UserID(), customID), nil
 		case "search":
 			return NewSearchCommandFromCustomID(customID), nil
+		case "recommend":
+			return NewRecommendCommandFromCustomID(c.interaction.GuildID, customID), nil
+		case "add-confirm":
+			return NewAddCommand(c.interaction.GuildID, c.UserID(), customID.Filter().Type, customID.Filter().Name, true), nil
+		case "poll-preview-reroll":
+			return NewPollPreviewRerollCommand(c.interaction.GuildID), nil
+		case "poll-preview-publish":
+			return NewPollPreviewPublishCommand(c.interaction.GuildID), nil
+		}
+		if strings.HasPrefix(customID.Type(), "rsvp-") {
+			return NewRsvpCommand(c.interaction.GuildID, c.UserID(), strings.TrimPrefix(customID.Type(), "rsvp-"), &c.interaction), nil
+		}
+		if strings.HasPrefix(customID.Type(), "hidden-vote-") {
+			choice, err := parseVoteIndex(customID.Type(), "hidden-vote-")
+			if err != nil {
+				return nil, fmt.Errorf("parseVoteIndex: %v", err)
+			}
+			return NewHiddenVoteCommand(c.interaction.GuildID, c.UserID(), choice, &c.interaction), nil
 		}
 	case discordgo.SelectMenuComponent:
 		switch customID.Type() {
 		case "add":
 			if len(msgData.Values) > 0 {
-				return NewAddCommand(c.interaction.GuildID, "game", msgData.Values[0]), nil
+				return NewAddCommand(c.interaction.GuildID, c.UserID(), "game", msgData.Values[0], false), nil
 			}
 			return nil, fmt.Errorf("no values provided: %v", msgData.Values)
+		case "poll-preview-lock":
+			return NewPollPreviewLockCommand(c.interaction.GuildID, msgData.Values), nil
+		case "ranked-vote-1", "ranked-vote-2", "ranked-vote-3":
+			rank, err := parseVoteIndex(customID.Type(), "ranked-vote-")
+			if err != nil {
+				return nil, fmt.Errorf("parseVoteIndex: %v", err)
+			}
+			return NewRankedVoteCommand(c.interaction.GuildID, c.UserID(), rank, msgData.Values, &c.interaction), nil
 		}
 	}
 	return nil, fmt.Errorf("unexpected message component: %v", msgData)
 }
 
+func (c *DiscordCommand) fromModalSubmit(ctx context.Context, cl *clients.Clients) (Command, error) {
+	if c.Type() != discordgo.InteractionModalSubmit {
+		return nil, fmt.Errorf("not a valid modal")
+	}
+	modalData := c.interaction.ModalSubmitData()
+	customID, err := customid.GetCustomID(ctx, modalData.CustomID, cl)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make(map[string]string)
+	for _, component := range modalData.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, rowComponent := range row.Components {
+			if input, ok := rowComponent.(*discordgo.TextInput); ok {
+				inputs[input.CustomID] = input.Value
+			}
+		}
+	}
+	if strings.HasPrefix(customID.Type(), "rate-") {
+		score, err := parseVoteIndex(customID.Type(), "rate-")
+		if err != nil {
+			return nil, fmt.Errorf("parseVoteIndex: %v", err)
+		}
+		return NewRateCommand(c.interaction.GuildID, c.UserID(), score, inputs["comment"], &c.interaction), nil
+	}
+	if customID.Type() == "edit" {
+		return NewEditDetailsCommand(c.interaction.GuildID, customID.Filter().Name, inputs), nil
+	}
+	return nil, fmt.Errorf("unexpected modal: %v", modalData.CustomID)
+}
+
 type Command interface {
 	Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error)
 }
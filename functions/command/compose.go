@@ -0,0 +1,475 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotchance/orderedmap/v2"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+const (
+	PINNED_PROVIDER          = "pinned"
+	NOMINATIONS_PROVIDER     = "nominations"
+	RANDOM_PROVIDER          = "random"
+	NEWEST_PROVIDER          = "newest"
+	OLDEST_UNPLAYED_PROVIDER = "oldest-unplayed"
+	GAMES_PROVIDER           = "games"
+	ACTIVITIES_PROVIDER      = "activities"
+	ROUND_ROBIN_PROVIDER     = "round-robin"
+)
+
+// Max number of times a random provider is queried to fill its slots
+const MAX_RANDOM_TRIES int = 5
+
+// slotProvider supplies candidates for a share of the poll entries
+type slotProvider struct {
+	emoji string
+	// random providers are queried again when they return entries that are already in the poll
+	random     bool
+	candidates func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error)
+}
+
+var slotProviders = map[string]slotProvider{
+	PINNED_PROVIDER: {
+		emoji: PINNED_EMOJI,
+		candidates: func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error) {
+			fow, err := g.GetFow(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("getFow: %v", err)
+			}
+			if fow == nil {
+				return []string{}, nil
+			}
+			return []string{*fow}, nil
+		},
+	},
+	NOMINATIONS_PROVIDER: {
+		emoji: NOMINATION_EMOJI,
+		candidates: func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error) {
+			fair, err := g.GetFairNominations(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("getFairNominations: %v", err)
+			}
+			if fair {
+				return fairNominations(ctx, g, cl)
+			}
+			ctxzap.Info(ctx, "Getting top nominations")
+			return activity.GetTopNominations(ctx, g.GetGuildId(), n, cl)
+		},
+	},
+	RANDOM_PROVIDER: {
+		emoji:  RANDOM_EMOJI,
+		random: true,
+		candidates: func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error) {
+			return activity.GetRandomActivities(ctx, g.GetGuildId(), n, &activity.RandomOptions{}, cl)
+		},
+	},
+	NEWEST_PROVIDER: {
+		emoji: "🆕",
+		candidates: func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error) {
+			return activity.GetNewestActivities(ctx, g.GetGuildId(), n, cl)
+		},
+	},
+	OLDEST_UNPLAYED_PROVIDER: {
+		emoji: "🕰️",
+		candidates: func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error) {
+			return activity.GetOldestUnplayedActivities(ctx, g.GetGuildId(), n, cl)
+		},
+	},
+	GAMES_PROVIDER: {
+		emoji:  "🎮",
+		random: true,
+		candidates: func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error) {
+			return activity.GetRandomActivities(ctx, g.GetGuildId(), n, &activity.RandomOptions{Type: activity.GAME}, cl)
+		},
+	},
+	ACTIVITIES_PROVIDER: {
+		emoji:  "🎯",
+		random: true,
+		candidates: func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error) {
+			return activity.GetRandomActivities(ctx, g.GetGuildId(), n, &activity.RandomOptions{Type: activity.ACTIVITY}, cl)
+		},
+	},
+	ROUND_ROBIN_PROVIDER: {
+		emoji: NOMINATION_EMOJI,
+		candidates: func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error) {
+			nominated, err := activity.GetNominatedActivities(ctx, g.GetGuildId(), cl)
+			if err != nil {
+				return nil, fmt.Errorf("getNominatedActivities: %v", err)
+			}
+			return roundRobinNominations(nominated, nil), nil
+		},
+	},
+}
+
+// fairNominations takes one nomination per nominator in turn, starting with
+// the nominators that have been left out of the most polls
+func fairNominations(ctx context.Context, g *guild.Guild, cl *clients.Clients) ([]string, error) {
+	ctxzap.Info(ctx, "Getting fair nominations")
+	nominated, err := activity.GetNominatedActivities(ctx, g.GetGuildId(), cl)
+	if err != nil {
+		return nil, fmt.Errorf("getNominatedActivities: %v", err)
+	}
+	leftOut, err := g.GetLeftOut(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getLeftOut: %v", err)
+	}
+	return roundRobinNominations(nominated, leftOut), nil
+}
+
+// trackLeftOutNominators records which nominators had none of their nominations make it into the poll
+func trackLeftOutNominators(ctx context.Context, g *guild.Guild, entries []guild.PollEntry, cl *clients.Clients) error {
+	nominated, err := activity.GetNominatedActivities(ctx, g.GetGuildId(), cl)
+	if err != nil {
+		return fmt.Errorf("getNominatedActivities: %v", err)
+	}
+	prevLeftOut, err := g.GetLeftOut(ctx)
+	if err != nil {
+		return fmt.Errorf("getLeftOut: %v", err)
+	}
+	included := make(map[string]bool)
+	for _, act := range nominated {
+		inPoll := slices.ContainsFunc(entries, func(entry guild.PollEntry) bool {
+			return entry.Name == act.Name
+		})
+		for _, user := range act.Nominations {
+			included[user] = included[user] || inPoll
+		}
+	}
+	leftOut := make(map[string]int)
+	for user, inPoll := range included {
+		if !inPoll {
+			leftOut[user] = prevLeftOut[user] + 1
+		}
+	}
+	ctxzap.Info(ctx, fmt.Sprintf("Nominators left out of the poll: %v", leftOut))
+	return g.SetLeftOut(ctx, leftOut)
+}
+
+type FairNominationsCommand struct {
+	GuildID string
+	Enabled bool
+}
+
+func NewFairNominationsCommand(guildID string, enabled bool) *FairNominationsCommand {
+	return &FairNominationsCommand{
+		GuildID: guildID,
+		Enabled: enabled,
+	}
+}
+
+func (c *FairNominationsCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	err = g.SetFairNominations(ctx, c.Enabled)
+	if err != nil {
+		return nil, fmt.Errorf("setFairNominations: %v", err)
+	}
+	if c.Enabled {
+		return utils.NewWebhookEdit("Nomination slots will be shared fairly between nominators"), nil
+	}
+	return utils.NewWebhookEdit("Nomination slots will go to the top nominations"), nil
+}
+
+// Providers used by guilds that haven't configured a composition. Each fills as many
+// of the remaining slots as it can and any left over are filled at random.
+var defaultProviders = []string{PINNED_PROVIDER, NOMINATIONS_PROVIDER}
+
+const DEFAULT_COMPOSITION_DESCRIPTION = "📌 The flavor of the week, then the top nominations. Random picks fill the rest"
+
+// roundRobinNominations orders nominations by taking each nominator's top remaining pick in turn.
+// Nominators with a higher priority pick first. Nominated activities are expected in order of nominations.
+func roundRobinNominations(nominated []activity.NominatedActivity, priority map[string]int) []string {
+	users := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, act := range nominated {
+		for _, user := range act.Nominations {
+			if !seen[user] {
+				seen[user] = true
+				users = append(users, user)
+			}
+		}
+	}
+	slices.SortStableFunc(users, func(a, b string) int {
+		return priority[b] - priority[a]
+	})
+
+	taken := make(map[string]bool)
+	results := make([]string, 0, len(nominated))
+	for {
+		added := false
+		for _, user := range users {
+			i := slices.IndexFunc(nominated, func(act activity.NominatedActivity) bool {
+				return !taken[act.Name] && slices.Contains(act.Nominations, user)
+			})
+			if i < 0 {
+				continue
+			}
+			taken[nominated[i].Name] = true
+			results = append(results, nominated[i].Name)
+			added = true
+		}
+		if !added {
+			return results
+		}
+	}
+}
+
+// allocateSlots splits total slots between the composition slots in proportion to their weights
+func allocateSlots(composition []guild.CompositionSlot, total int) []int {
+	slots := make([]int, len(composition))
+	weightSum := 0
+	for _, slot := range composition {
+		weightSum += slot.Weight
+	}
+	if weightSum <= 0 {
+		return slots
+	}
+	// Largest remainder method
+	remainders := make([]int, len(composition))
+	allocated := 0
+	for i, slot := range composition {
+		slots[i] = slot.Weight * total / weightSum
+		remainders[i] = slot.Weight * total % weightSum
+		allocated += slots[i]
+	}
+	order := make([]int, len(composition))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return remainders[b] - remainders[a]
+	})
+	for _, i := range order {
+		if allocated >= total {
+			break
+		}
+		slots[i] += 1
+		allocated += 1
+	}
+	return slots
+}
+
+// composePoll runs each provider of the composition to fill its share of the poll.
+// Entries already in the poll count towards the share of the provider that supplied them.
+// An empty composition uses the default providers.
+func composePoll(ctx context.Context, g *guild.Guild, composition []guild.CompositionSlot, total int, answers *orderedmap.OrderedMap[string, answerEntry], cl *clients.Clients) error {
+	if len(composition) == 0 {
+		for _, name := range defaultProviders {
+			err := fillFromProvider(ctx, g, name, slotProviders[name], total-answers.Len(), answers, cl)
+			if err != nil {
+				return fmt.Errorf("%v: %v", name, err)
+			}
+		}
+	}
+	slots := allocateSlots(composition, total)
+	for i, slot := range composition {
+		provider, ok := slotProviders[slot.Provider]
+		if !ok {
+			ctxzap.Warn(ctx, fmt.Sprintf("Unknown slot provider: %v", slot.Provider))
+			continue
+		}
+		quota := slots[i]
+		for el := answers.Front(); el != nil; el = el.Next() {
+			if el.Value.source == slot.Provider {
+				quota -= 1
+			}
+		}
+		err := fillFromProvider(ctx, g, slot.Provider, provider, min(quota, total-answers.Len()), answers, cl)
+		if err != nil {
+			return fmt.Errorf("%v: %v", slot.Provider, err)
+		}
+	}
+	// Fill any remaining slots at random
+	return fillFromProvider(ctx, g, RANDOM_PROVIDER, slotProviders[RANDOM_PROVIDER], total-answers.Len(), answers, cl)
+}
+
+func fillFromProvider(ctx context.Context, g *guild.Guild, name string, provider slotProvider, quota int, answers *orderedmap.OrderedMap[string, answerEntry], cl *clients.Clients) error {
+	added := 0
+	for tries := 0; added < quota && tries < MAX_RANDOM_TRIES; tries++ {
+		ctxzap.Info(ctx, fmt.Sprintf("Getting %v entries. Try %v", name, tries))
+		// Ask for extra candidates in case some are already in the poll
+		candidates, err := provider.candidates(ctx, g, quota-added+answers.Len(), cl)
+		if err != nil {
+			return err
+		}
+		candidates, err = filterCandidates(ctx, g, candidates, cl)
+		if err != nil {
+			return fmt.Errorf("filterCandidates: %v", err)
+		}
+		for _, candidate := range candidates {
+			if added >= quota {
+				break
+			}
+			if _, ok := answers.Get(candidate); ok {
+				continue
+			}
+			answers.Set(candidate, answerEntry{
+				emoji:  provider.emoji,
+				source: name,
+			})
+			added += 1
+		}
+		if !provider.random {
+			break
+		}
+	}
+	return nil
+}
+
+// applyActivityEmojis replaces the provider emoji of entries whose activity has its own poll emoji
+func applyActivityEmojis(ctx context.Context, g *guild.Guild, answers *orderedmap.OrderedMap[string, answerEntry], cl *clients.Clients) error {
+	names := make([]string, 0, answers.Len())
+	for el := answers.Front(); el != nil; el = el.Next() {
+		names = append(names, el.Key)
+	}
+	acts, err := activity.GetInnerActivities(ctx, g.GetGuildId(), names, cl)
+	if err != nil {
+		return fmt.Errorf("getInnerActivities: %v", err)
+	}
+	for el := answers.Front(); el != nil; el = el.Next() {
+		if act, ok := acts[el.Key]; ok && act.Emoji != "" {
+			el.Value.emoji = act.Emoji
+		}
+	}
+	return nil
+}
+
+// filterCandidates drops the candidates that don't fit the expected players. Games owned by
+// too few members are dropped when ownership is required or moved to the end when it is preferred.
+func filterCandidates(ctx context.Context, g *guild.Guild, candidates []string, cl *clients.Clients) ([]string, error) {
+	players, err := g.GetExpectedPlayers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getExpectedPlayers: %v", err)
+	}
+	ownership, err := g.GetOwnership(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getOwnership: %v", err)
+	}
+	if players <= 0 && ownership == nil {
+		return candidates, nil
+	}
+	acts, err := activity.GetInnerActivities(ctx, g.GetGuildId(), candidates, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getInnerActivities: %v", err)
+	}
+	results := make([]string, 0, len(candidates))
+	unowned := make([]string, 0)
+	for _, name := range candidates {
+		act, ok := acts[name]
+		if !ok {
+			results = append(results, name)
+			continue
+		}
+		if players > 0 && !act.FitsPlayers(players) {
+			continue
+		}
+		if ownership != nil && act.Typ == activity.GAME && len(act.Owners) < ownership.MinOwners {
+			if !ownership.Required {
+				unowned = append(unowned, name)
+			}
+			continue
+		}
+		results = append(results, name)
+	}
+	return append(results, unowned...), nil
+}
+
+func parseComposition(text string) ([]guild.CompositionSlot, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	composition := make([]guild.CompositionSlot, 0, len(fields))
+	for _, field := range fields {
+		provider, weightText, found := strings.Cut(field, ":")
+		weight := 1
+		if found {
+			var err error
+			weight, err = strconv.Atoi(weightText)
+			if err != nil || weight < 1 {
+				return nil, fmt.Errorf("invalid weight for %v: %v", provider, weightText)
+			}
+		}
+		if _, ok := slotProviders[provider]; !ok {
+			return nil, fmt.Errorf("unknown slot provider: %v", provider)
+		}
+		composition = append(composition, guild.CompositionSlot{
+			Provider: provider,
+			Weight:   weight,
+		})
+	}
+	return composition, nil
+}
+
+func formatComposition(composition []guild.CompositionSlot) string {
+	slots := allocateSlots(composition, MAX_POLL_ENTRIES)
+	lines := make([]string, 0, len(composition))
+	for i, slot := range composition {
+		lines = append(lines, fmt.Sprintf("%v `%v:%v` (%v slots)", slotProviders[slot.Provider].emoji, slot.Provider, slot.Weight, slots[i]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+type PollCompositionCommand struct {
+	GuildID     string
+	Composition string
+}
+
+func NewPollCompositionCommand(guildID, composition string) *PollCompositionCommand {
+	return &PollCompositionCommand{
+		GuildID:     guildID,
+		Composition: composition,
+	}
+}
+
+func (c *PollCompositionCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	if c.Composition == "" {
+		composition, err := g.GetPollComposition(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getPollComposition: %v", err)
+		}
+		if len(composition) == 0 {
+			return utils.NewWebhookEdit(fmt.Sprintf("Poll composition:\n%v", DEFAULT_COMPOSITION_DESCRIPTION)), nil
+		}
+		return utils.NewWebhookEdit(fmt.Sprintf("Poll composition:\n%v", formatComposition(composition))), nil
+	}
+	var composition []guild.CompositionSlot
+	if c.Composition != "default" {
+		composition, err = parseComposition(c.Composition)
+		if err != nil {
+			return utils.NewWebhookEdit(fmt.Sprintf("Invalid composition: %v\nAvailable providers: %v", err, strings.Join(providerNames(), ", "))), nil
+		}
+	}
+	err = g.SetPollComposition(ctx, composition)
+	if err != nil {
+		return nil, fmt.Errorf("setPollComposition: %v", err)
+	}
+	if len(composition) == 0 {
+		return utils.NewWebhookEdit(fmt.Sprintf("Set poll composition:\n%v", DEFAULT_COMPOSITION_DESCRIPTION)), nil
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Set poll composition:\n%v", formatComposition(composition))), nil
+}
+
+func providerNames() []string {
+	names := make([]string, 0, len(slotProviders))
+	for name := range slotProviders {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
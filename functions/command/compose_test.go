@@ -0,0 +1,53 @@
+package command
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+)
+
+func TestAllocateSlots(t *testing.T) {
+	tests := []struct {
+		composition []guild.CompositionSlot
+		total       int
+		want        []int
+	}{
+		{[]guild.CompositionSlot{{Provider: "a", Weight: 1}, {Provider: "b", Weight: 2}, {Provider: "c", Weight: 4}}, 7, []int{1, 2, 4}},
+		{[]guild.CompositionSlot{{Provider: "a", Weight: 1}, {Provider: "b", Weight: 1}}, 7, []int{4, 3}},
+		{[]guild.CompositionSlot{{Provider: "a", Weight: 3}, {Provider: "b", Weight: 3}, {Provider: "c", Weight: 1}}, 5, []int{2, 2, 1}},
+		{[]guild.CompositionSlot{{Provider: "a", Weight: 1}, {Provider: "b", Weight: 1}}, 0, []int{0, 0}},
+		{[]guild.CompositionSlot{}, 7, []int{}},
+	}
+	for _, test := range tests {
+		if got := allocateSlots(test.composition, test.total); !slices.Equal(got, test.want) {
+			t.Errorf(`allocateSlots(%v, %v) = %v, want %v`, test.composition, test.total, got, test.want)
+		}
+	}
+}
+
+func TestParseComposition(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []guild.CompositionSlot
+		wantErr bool
+	}{
+		{"pinned, nominations:5, random:1", []guild.CompositionSlot{{Provider: "pinned", Weight: 1}, {Provider: "nominations", Weight: 5}, {Provider: "random", Weight: 1}}, false},
+		{"newest:2 oldest-unplayed", []guild.CompositionSlot{{Provider: "newest", Weight: 2}, {Provider: "oldest-unplayed", Weight: 1}}, false},
+		{"", []guild.CompositionSlot{}, false},
+		{"unknown", nil, true},
+		{"random:0", nil, true},
+		{"random:-1", nil, true},
+		{"random:x", nil, true},
+	}
+	for _, test := range tests {
+		got, err := parseComposition(test.text)
+		if (err != nil) != test.wantErr {
+			t.Errorf(`parseComposition(%q) err = %v, want error %v`, test.text, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && !slices.Equal(got, test.want) {
+			t.Errorf(`parseComposition(%q) = %v, want %v`, test.text, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+type EarlyCloseCommand struct {
+	GuildID string
+	Enabled bool
+	RoleID  string
+	Voters  int
+}
+
+func NewEarlyCloseCommand(guildID string, enabled bool, roleID string, voters int) *EarlyCloseCommand {
+	return &EarlyCloseCommand{
+		GuildID: guildID,
+		Enabled: enabled,
+		RoleID:  roleID,
+		Voters:  voters,
+	}
+}
+
+func (c *EarlyCloseCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	if !c.Enabled {
+		err = g.ClearEarlyClose(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("clearEarlyClose: %v", err)
+		}
+		return utils.NewWebhookEdit("Polls will run until they expire"), nil
+	}
+	err = g.SetEarlyClose(ctx, &guild.EarlyCloseInfo{
+		Voters: c.Voters,
+		RoleID: c.RoleID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("setEarlyClose: %v", err)
+	}
+	eligible := "every member"
+	if c.Voters > 0 {
+		eligible = fmt.Sprintf("%v voters", c.Voters)
+	} else if c.RoleID != "" {
+		eligible = fmt.Sprintf("the members of <@&%v>", c.RoleID)
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Polls will end early once %v have voted or the lead can't be beaten", eligible)), nil
+}
+
+type CheckEarlyCloseCommand struct {
+	GuildID string
+}
+
+func NewCheckEarlyCloseCommand(guildID string) *CheckEarlyCloseCommand {
+	return &CheckEarlyCloseCommand{
+		GuildID: guildID,
+	}
+}
+
+// Execute ends the active poll if its outcome can no longer change
+func (c *CheckEarlyCloseCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	earlyClose, err := g.GetEarlyClose(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getEarlyClose: %v", err)
+	}
+	if earlyClose == nil {
+		return utils.NewWebhookEdit("Early close is not enabled"), nil
+	}
+	pollInfo, err := g.GetActivePoll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getActivePoll: %v", err)
+	}
+	if pollInfo == nil {
+		return utils.NewWebhookEdit("There is no active poll"), nil
+	}
+	s, err := cl.Discord()
+	if err != nil {
+		return nil, fmt.Errorf("discord: %v", err)
+	}
+
+	// Vote counts per answer. Left empty when the lead can't be worked out from them
+	counts := make([]int, 0)
+	voterIDs := make(map[string]bool)
+	if pollInfo.IsComponentPoll() {
+		ballots := castBallots(pollInfo)
+		for user := range ballots {
+			voterIDs[user] = true
+		}
+		if pollInfo.Hidden {
+			tally := tallyVotes(ballots)
+			for _, name := range pollCandidates(pollInfo) {
+				counts = append(counts, tally[name])
+			}
+		}
+	} else {
+		msg, err := s.ChannelMessage(pollInfo.ChannelID, pollInfo.MessageID)
+		if err != nil {
+			return nil, fmt.Errorf("channelMessage: %v", err)
+		}
+		if msg.Poll == nil || (msg.Poll.Expiry != nil && msg.Poll.Expiry.Before(time.Now())) {
+			return utils.NewWebhookEdit("The poll has already ended"), nil
+		}
+		voters, err := getPollVoters(s, pollInfo.ChannelID, pollInfo.MessageID, msg.Poll)
+		if err != nil {
+			return nil, fmt.Errorf("getPollVoters: %v", err)
+		}
+		for _, users := range voters {
+			counts = append(counts, len(users))
+			for _, user := range users {
+				voterIDs[user] = true
+			}
+		}
+		// Votes carried over from the poll this extends can still be replaced
+		for user := range pollInfo.CarriedVotes {
+			voterIDs[user] = true
+		}
+	}
+
+	eligible := earlyClose.Voters
+	voted := min(len(voterIDs), eligible)
+	if eligible <= 0 {
+		members, err := getRoleMembers(s, c.GuildID, earlyClose.RoleID)
+		if err != nil {
+			return nil, fmt.Errorf("getRoleMembers: %v", err)
+		}
+		eligible = len(members)
+		// Only eligible voters bring the poll closer to ending
+		voted = 0
+		for _, member := range members {
+			if voterIDs[member] {
+				voted++
+			}
+		}
+	}
+
+	// Every poll type lets voters change their vote until it closes
+	switchable := len(voterIDs)
+	remaining := eligible - voted
+	ctxzap.Info(ctx, fmt.Sprintf("%v of %v eligible voters have voted. Counts: %v", voted, eligible, counts))
+	if eligible <= 0 || (remaining > 0 && !isLeadSafe(counts, remaining, switchable)) {
+		return utils.NewWebhookEdit("The outcome of the poll is not decided yet"), nil
+	}
+	ctxzap.Info(ctx, "Poll outcome is decided. Ending early")
+	return NewEndPollCommand(c.GuildID).Execute(ctx, cl)
+}
+
+// isLeadSafe returns whether the leading answer stays ahead even if every remaining voter picks the runner up
+// and every switchable vote moves from the leader to the runner up
+func isLeadSafe(counts []int, remaining, switchable int) bool {
+	if len(counts) == 0 {
+		return false
+	}
+	sorted := slices.Clone(counts)
+	slices.Sort(sorted)
+	slices.Reverse(sorted)
+	runnerUp := 0
+	if len(sorted) > 1 {
+		runnerUp = sorted[1]
+	}
+	// A switched vote takes one from the leader and gives one to the runner up
+	return sorted[0]-runnerUp > remaining+2*switchable
+}
@@ -0,0 +1,31 @@
+package command
+
+import "testing"
+
+func TestIsLeadSafe(t *testing.T) {
+	tests := []struct {
+		counts     []int
+		remaining  int
+		switchable int
+		want       bool
+	}{
+		{[]int{}, 0, 0, false},
+		{[]int{5}, 0, 0, true},
+		{[]int{0}, 0, 0, false},
+		{[]int{5, 3}, 1, 0, true},
+		{[]int{5, 3}, 2, 0, false},
+		{[]int{3, 5}, 1, 0, true},
+		{[]int{4, 4}, 0, 0, false},
+		{[]int{2, 7, 1}, 4, 0, true},
+		{[]int{2, 7, 1}, 5, 0, false},
+		{[]int{5, 3}, 0, 1, false},
+		{[]int{9, 1}, 1, 3, true},
+		{[]int{9, 1}, 2, 3, false},
+		{[]int{5, 0}, 0, 5, false},
+	}
+	for _, test := range tests {
+		if got := isLeadSafe(test.counts, test.remaining, test.switchable); got != test.want {
+			t.Errorf(`isLeadSafe(%v, %v, %v) = %v, want %v`, test.counts, test.remaining, test.switchable, got, test.want)
+		}
+	}
+}
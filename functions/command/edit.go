@@ -0,0 +1,414 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/customid"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/dimuska139/rawg-sdk-go/v3"
+)
+
+const MAX_DESCRIPTION_LENGTH int = 1000
+
+const MAX_LINKS int = 5
+
+// Max length of a modal title
+const MAX_MODAL_TITLE_LENGTH int = 45
+
+// Clears the poll emoji of an activity
+const CLEAR_EMOJI = "none"
+
+// editModal opens the details editor when /edit is run without any options to apply directly
+func editModal(ctx context.Context, interaction *discordgo.Interaction, cl *clients.Clients) (*discordgo.InteractionResponse, error) {
+	args := utils.OptionsToMap(interaction.ApplicationCommandData().Options)
+	if len(args) != 1 {
+		return nil, nil
+	}
+	nameOpt, ok := args["name"]
+	if !ok {
+		return nil, nil
+	}
+	act, err := activity.GetActivity(ctx, nameOpt.StringValue(), interaction.GuildID, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			return &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: fmt.Sprintf("%v does not exist", nameOpt.StringValue()),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			}, nil
+		}
+		return nil, fmt.Errorf("getActivity: %v", err)
+	}
+	customID, err := customid.CreateCustomID(ctx, "edit", customid.Filter{
+		Name: act.Name(),
+	}, 0, cl)
+	if err != nil {
+		return nil, fmt.Errorf("CreateCustomID: %v", err)
+	}
+	discordCustomID, err := customID.ToDiscordCustomID()
+	if err != nil {
+		return nil, fmt.Errorf("ToDiscordCustomID: %v", err)
+	}
+
+	title := []rune("Edit " + act.Name())
+	if len(title) > MAX_MODAL_TITLE_LENGTH {
+		title = append(title[:MAX_MODAL_TITLE_LENGTH-1], '…')
+	}
+	description, links, imageURL := act.Details()
+	inputs := []discordgo.TextInput{
+		{
+			CustomID:  "description",
+			Label:     "Description",
+			Style:     discordgo.TextInputParagraph,
+			Value:     description,
+			MaxLength: MAX_DESCRIPTION_LENGTH,
+		},
+		{
+			CustomID:    "links",
+			Label:       "Links",
+			Style:       discordgo.TextInputParagraph,
+			Placeholder: "One link per line",
+			Value:       strings.Join(links, "\n"),
+		},
+		{
+			CustomID:    "tags",
+			Label:       "Tags",
+			Style:       discordgo.TextInputShort,
+			Placeholder: "Comma separated tags",
+			Value:       strings.Join(act.Tags(), ", "),
+		},
+		{
+			CustomID:    "players",
+			Label:       "Players",
+			Style:       discordgo.TextInputShort,
+			Placeholder: "2-4, 4 or 2+",
+			Value:       activity.FormatPlayerCount(act.PlayerCount()),
+		},
+		{
+			CustomID:    "image",
+			Label:       "Custom image URL",
+			Style:       discordgo.TextInputShort,
+			Placeholder: "https://",
+			Value:       imageURL,
+		},
+	}
+	rows := make([]discordgo.MessageComponent, 0, len(inputs))
+	for _, input := range inputs {
+		rows = append(rows, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{input},
+		})
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID:   discordCustomID,
+			Title:      string(title),
+			Components: rows,
+		},
+	}, nil
+}
+
+type EditActivityCommand struct {
+	GuildID string
+	Name    string
+	// "game" or "activity". Empty keeps the type
+	ActivityType string
+	// RAWG slug to link the game to
+	Game  string
+	Emoji string
+}
+
+func NewEditActivityCommand(guildID, name, activityType, game, emoji string) *EditActivityCommand {
+	return &EditActivityCommand{
+		GuildID:      guildID,
+		Name:         name,
+		ActivityType: activityType,
+		Game:         game,
+		Emoji:        emoji,
+	}
+}
+
+func (c *EditActivityCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	if c.Game != "" && c.ActivityType == "activity" {
+		return utils.NewWebhookEdit("An activity can't be linked to a game"), nil
+	}
+	if c.Emoji != "" && c.Emoji != CLEAR_EMOJI && !validEmoji(c.Emoji) {
+		return utils.NewWebhookEdit(fmt.Sprintf("%v is not an emoji", c.Emoji)), nil
+	}
+	act, err := activity.GetActivity(ctx, c.Name, c.GuildID, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			return utils.NewWebhookEdit(fmt.Sprintf("%v does not exist", c.Name)), nil
+		}
+		return nil, fmt.Errorf("getActivity: %v", err)
+	}
+
+	changes := make([]string, 0, 2)
+	switch {
+	case c.Game != "":
+		detail, err := cl.Rawg().GetGame(ctx, c.Game)
+		if err != nil {
+			if rawgError, ok := err.(*rawg.RawgError); ok && rawgError.HttpCode == http.StatusNotFound {
+				return utils.NewWebhookEdit("🚧 Game not found 🚧"), nil
+			}
+			return nil, fmt.Errorf("rawg.go: %v", err)
+		}
+		if detail == nil {
+			return utils.NewWebhookEdit("🚧 Game not found 🚧"), nil
+		}
+		err = act.SetType(ctx, activity.GAME, &activity.GameInfo{
+			Id:              detail.ID,
+			Slug:            detail.Slug,
+			BackgroundImage: detail.ImageBackground,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("act.SetType: %v", err)
+		}
+		changes = append(changes, fmt.Sprintf("Linked to %v", detail.Name))
+	case c.ActivityType == "game" && act.Typ() != activity.GAME:
+		err = act.SetType(ctx, activity.GAME, nil)
+		if err != nil {
+			return nil, fmt.Errorf("act.SetType: %v", err)
+		}
+		changes = append(changes, "Changed to a game")
+	case c.ActivityType == "activity" && act.Typ() != activity.ACTIVITY:
+		err = act.SetType(ctx, activity.ACTIVITY, nil)
+		if err != nil {
+			return nil, fmt.Errorf("act.SetType: %v", err)
+		}
+		changes = append(changes, "Changed to an activity")
+	}
+	if c.Emoji != "" {
+		emoji := c.Emoji
+		if emoji == CLEAR_EMOJI {
+			emoji = ""
+		}
+		err = act.SetEmoji(ctx, emoji)
+		if err != nil {
+			return nil, fmt.Errorf("act.SetEmoji: %v", err)
+		}
+		if emoji == "" {
+			changes = append(changes, "Cleared the poll emoji")
+		} else {
+			changes = append(changes, fmt.Sprintf("Poll emoji set to %v", emoji))
+		}
+	}
+	if len(changes) == 0 {
+		return utils.NewWebhookEdit(fmt.Sprintf("Nothing to change for %v", act.Name())), nil
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Updated %v\n%v", act.Name(), strings.Join(changes, "\n"))), nil
+}
+
+type EditDetailsCommand struct {
+	GuildID     string
+	Name        string
+	Description string
+	Links       string
+	Tags        string
+	Players     string
+	ImageURL    string
+}
+
+func NewEditDetailsCommand(guildID, name string, inputs map[string]string) *EditDetailsCommand {
+	return &EditDetailsCommand{
+		GuildID:     guildID,
+		Name:        name,
+		Description: inputs["description"],
+		Links:       inputs["links"],
+		Tags:        inputs["tags"],
+		Players:     inputs["players"],
+		ImageURL:    inputs["image"],
+	}
+}
+
+func (c *EditDetailsCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	description := strings.TrimSpace(c.Description)
+	if utf8.RuneCountInString(description) > MAX_DESCRIPTION_LENGTH {
+		return utils.NewWebhookEdit(fmt.Sprintf("The description can be at most %v characters", MAX_DESCRIPTION_LENGTH)), nil
+	}
+	links := strings.Fields(c.Links)
+	if len(links) > MAX_LINKS {
+		return utils.NewWebhookEdit(fmt.Sprintf("There can be at most %v links", MAX_LINKS)), nil
+	}
+	for _, link := range links {
+		if !validURL(link) {
+			return utils.NewWebhookEdit(fmt.Sprintf("Invalid link: %v", link)), nil
+		}
+	}
+	imageURL := strings.TrimSpace(c.ImageURL)
+	if imageURL != "" && !validURL(imageURL) {
+		return utils.NewWebhookEdit(fmt.Sprintf("Invalid image URL: %v", imageURL)), nil
+	}
+	tags := activity.NormalizeTags(c.Tags)
+	if len(tags) > MAX_TAGS {
+		return utils.NewWebhookEdit(fmt.Sprintf("An activity can have at most %v tags", MAX_TAGS)), nil
+	}
+	minPlayers, maxPlayers, err := parsePlayerCount(c.Players)
+	if err != nil {
+		return utils.NewWebhookEdit(fmt.Sprintf("Invalid player count: %v", err)), nil
+	}
+
+	act, err := activity.GetActivity(ctx, c.Name, c.GuildID, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			return utils.NewWebhookEdit(fmt.Sprintf("%v does not exist", c.Name)), nil
+		}
+		return nil, fmt.Errorf("getActivity: %v", err)
+	}
+	err = act.SetDetails(ctx, description, links, imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("act.SetDetails: %v", err)
+	}
+	err = act.SetTags(ctx, tags)
+	if err != nil {
+		return nil, fmt.Errorf("act.SetTags: %v", err)
+	}
+	err = act.SetPlayerCount(ctx, minPlayers, maxPlayers)
+	if err != nil {
+		return nil, fmt.Errorf("act.SetPlayerCount: %v", err)
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Updated %v", act.Name())), nil
+}
+
+// parsePlayerCount parses counts like "2-4", "4" or "2+". Empty text is an unknown count
+func parsePlayerCount(text string) (int, int, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return 0, 0, nil
+	}
+	if minText, found := strings.CutSuffix(text, "+"); found {
+		minPlayers, err := strconv.Atoi(strings.TrimSpace(minText))
+		if err != nil || minPlayers < 1 {
+			return 0, 0, fmt.Errorf("%v", text)
+		}
+		return minPlayers, 0, nil
+	}
+	minText, maxText, found := strings.Cut(text, "-")
+	if !found {
+		maxText = minText
+	}
+	minPlayers, err := strconv.Atoi(strings.TrimSpace(minText))
+	if err != nil || minPlayers < 1 {
+		return 0, 0, fmt.Errorf("%v", text)
+	}
+	maxPlayers, err := strconv.Atoi(strings.TrimSpace(maxText))
+	if err != nil || maxPlayers < minPlayers {
+		return 0, 0, fmt.Errorf("%v", text)
+	}
+	return minPlayers, maxPlayers, nil
+}
+
+func validURL(text string) bool {
+	u, err := url.Parse(text)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// Longest emoji sequence accepted, e.g. family emojis joined by ZWJs or subdivision flags
+const MAX_EMOJI_RUNES int = 16
+
+const (
+	ZERO_WIDTH_JOINER  = '\u200d'
+	COMBINING_KEYCAP   = '\u20e3'
+	VARIATION_TEXT     = '\ufe0e'
+	VARIATION_EMOJI    = '\ufe0f'
+	SKIN_TONE_FIRST    = '\U0001f3fb'
+	SKIN_TONE_LAST     = '\U0001f3ff'
+	TAG_FIRST          = '\U000e0020'
+	TAG_LAST           = '\U000e007f'
+	REGIONAL_FIRST     = '\U0001f1e6'
+	REGIONAL_LAST      = '\U0001f1ff'
+	KEYCAP_BASE_SYMBOL = "#*"
+)
+
+// emojiRanges are the blocks holding the pictographs that can start an emoji
+var emojiRanges = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00a9, Hi: 0x00a9, Stride: 1},
+		{Lo: 0x00ae, Hi: 0x00ae, Stride: 1},
+		{Lo: 0x203c, Hi: 0x203c, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+		{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+		{Lo: 0x2194, Hi: 0x21aa, Stride: 1},
+		{Lo: 0x231a, Hi: 0x23ff, Stride: 1},
+		{Lo: 0x24c2, Hi: 0x24c2, Stride: 1},
+		{Lo: 0x25aa, Hi: 0x25fe, Stride: 1},
+		{Lo: 0x2600, Hi: 0x27bf, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2b05, Hi: 0x2b55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303d, Hi: 0x303d, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3297, Stride: 1},
+		{Lo: 0x3299, Hi: 0x3299, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1faff, Stride: 1},
+	},
+}
+
+// validEmoji checks for a single unicode emoji, possibly joined with ZWJs or modified by
+// skin tones, variation selectors, keycaps or tags. Discord polls don't accept custom emojis by name
+func validEmoji(text string) bool {
+	runes := []rune(text)
+	if len(runes) == 0 || len(runes) > MAX_EMOJI_RUNES {
+		return false
+	}
+	// Whether the previous rune was an emoji that can be modified or joined
+	afterEmoji := false
+	// Whether the emoji so far is a single regional indicator, the first half of a flag
+	halfFlag := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		isRegional := r >= REGIONAL_FIRST && r <= REGIONAL_LAST
+		switch {
+		case unicode.Is(emojiRanges, r) && !(r >= SKIN_TONE_FIRST && r <= SKIN_TONE_LAST):
+			// Emojis next to each other are only one emoji as a flag
+			if afterEmoji && !(halfFlag && isRegional) {
+				return false
+			}
+			halfFlag = isRegional && !halfFlag
+			afterEmoji = true
+		case (r >= '0' && r <= '9') || strings.ContainsRune(KEYCAP_BASE_SYMBOL, r):
+			// Digits and symbols are only emojis as keycaps e.g. 1️⃣
+			if afterEmoji {
+				return false
+			}
+			if i+1 < len(runes) && runes[i+1] == VARIATION_EMOJI {
+				i++
+			}
+			if i+1 >= len(runes) || runes[i+1] != COMBINING_KEYCAP {
+				return false
+			}
+			i++
+			afterEmoji = true
+		case r == VARIATION_EMOJI || r == VARIATION_TEXT || (r >= SKIN_TONE_FIRST && r <= SKIN_TONE_LAST) || (r >= TAG_FIRST && r <= TAG_LAST):
+			if !afterEmoji {
+				return false
+			}
+		case r == ZERO_WIDTH_JOINER:
+			if !afterEmoji || i+1 >= len(runes) {
+				return false
+			}
+			afterEmoji = false
+			halfFlag = false
+		default:
+			return false
+		}
+	}
+	return afterEmoji
+}
@@ -0,0 +1,74 @@
+package command
+
+import "testing"
+
+func TestValidEmoji(t *testing.T) {
+	tests := []struct {
+		emoji string
+		want  bool
+	}{
+		{"🎮", true},
+		{"⭐", true},
+		{"❤️", true},
+		{"👍🏽", true},
+		{"👨‍👩‍👧‍👦", true},
+		{"🏳️‍🌈", true},
+		{"🇯🇵", true},
+		{"🏴󠁧󠁢󠁳󠁣󠁴󠁿", true},
+		{"1️⃣", true},
+		{"#⃣", true},
+		{"", false},
+		{"123", false},
+		{"!!", false},
+		{"#1", false},
+		{"a", false},
+		{"🎮 ", false},
+		{":smile:", false},
+		{"<:custom:123>", false},
+		{"‍🎮", false},
+		{"🎮‍", false},
+		{"️", false},
+		{"🏽", false},
+		{"🎮🎮", false},
+		{"🇯🇵🇯🇵", false},
+		{"🎮1️⃣", false},
+	}
+	for _, test := range tests {
+		if got := validEmoji(test.emoji); got != test.want {
+			t.Errorf(`validEmoji(%q) = %v, want %v`, test.emoji, got, test.want)
+		}
+	}
+}
+
+func TestParsePlayerCount(t *testing.T) {
+	tests := []struct {
+		text    string
+		wantMin int
+		wantMax int
+		wantErr bool
+	}{
+		{"", 0, 0, false},
+		{" 4 ", 4, 4, false},
+		{"1", 1, 1, false},
+		{"2-4", 2, 4, false},
+		{"2 - 4", 2, 4, false},
+		{"2+", 2, 0, false},
+		{"0", 0, 0, true},
+		{"0+", 0, 0, true},
+		{"4-2", 0, 0, true},
+		{"2-", 0, 0, true},
+		{"2-b", 0, 0, true},
+		{"-1", 0, 0, true},
+		{"a", 0, 0, true},
+	}
+	for _, test := range tests {
+		gotMin, gotMax, err := parsePlayerCount(test.text)
+		if (err != nil) != test.wantErr {
+			t.Errorf(`parsePlayerCount(%q) err = %v, want error %v`, test.text, err, test.wantErr)
+			continue
+		}
+		if gotMin != test.wantMin || gotMax != test.wantMax {
+			t.Errorf(`parsePlayerCount(%q) = %v, %v, want %v, %v`, test.text, gotMin, gotMax, test.wantMin, test.wantMax)
+		}
+	}
+}
@@ -38,9 +38,10 @@ func (c *SetFowCommand) Execute(ctx context.Context, cl *clients.Clients) (*disc
 		return nil, err
 	}
 
-	err = g.SetFow(ctx, c.Name)
+	// An override starts a new week just like a poll win
+	err = declareWinner(ctx, c.Name, c.GuildID, g, cl)
 	if err != nil {
-		return nil, fmt.Errorf("SetFow: %v", err)
+		return nil, fmt.Errorf("declareWinner: %v", err)
 	}
 	return utils.NewWebhookEdit(fmt.Sprintf("Set flavor of the week to %v", c.Name)), nil
 
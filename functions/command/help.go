@@ -36,13 +36,17 @@ Here are some of the important commands:`,
 					{
 						Name: "The pool",
 						Value: "The pool holds all games and activites. You can view the pool with `/pool`\n" +
-							"You can remove an item with `/remove`",
+							"Admins can edit an item with `/edit` or rename it with `/rename`. Remove items with `/remove`\n" +
+							"Tag items with `/tag` and filter the pool by tag with `/pool tag`\n" +
+							"Admins can give items nicknames with `/alias`\n" +
+							"See details and ratings of an item with `/activity-info`",
 					},
 					{
 						Name: "Adding a game or activity to the pool",
 						Value: "You can add a game by\n" +
 							" 1. Adding it with `/add`\n" +
-							" 2. Searching for a game with `/search` and then selecting the game from the results",
+							" 2. Searching for a game with `/search` and then selecting the game from the results\n" +
+							" 3. Picking one of the games suggested by `/recommend`",
 					},
 					{
 						Name: "Nominations",
@@ -0,0 +1,185 @@
+package command
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/bwmarrin/discordgo"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// Max characters in a button label
+const MAX_BUTTON_LABEL int = 80
+
+const BUTTONS_PER_ROW int = 5
+
+// buildHiddenPoll renders a hidden poll. The tally is only shown once results are given.
+func buildHiddenPoll(pollInfo *guild.PollInfo, results map[string]int) ([]*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	candidates := pollCandidates(pollInfo)
+	emojis := make(map[string]string)
+	for i, name := range candidates {
+		emojis[name] = "🎲"
+		if i < len(pollInfo.Entries) {
+			emojis[name] = pollInfo.Entries[i].Emoji
+		}
+	}
+
+	components := make([]discordgo.MessageComponent, 0)
+	if results != nil {
+		ranking := slices.Clone(candidates)
+		slices.SortStableFunc(ranking, func(a, b string) int {
+			return cmp.Compare(results[b], results[a])
+		})
+		lines := make([]string, 0, len(ranking))
+		for _, name := range ranking {
+			line := fmt.Sprintf("%v %v - %v votes", emojis[name], name, results[name])
+			if results[name] > 0 && results[name] == results[ranking[0]] {
+				line = fmt.Sprintf("**%v**", line)
+			}
+			lines = append(lines, line)
+		}
+		embeds := []*discordgo.MessageEmbed{
+			{
+				Title:       componentPollTitle(pollInfo),
+				Description: strings.Join(lines, "\n"),
+				Color:       2326507,
+				Footer: &discordgo.MessageEmbedFooter{
+					Text: fmt.Sprintf("%v votes cast • Voting has closed", len(castBallots(pollInfo))),
+				},
+			},
+		}
+		return embeds, components
+	}
+
+	lines := make([]string, 0, len(candidates))
+	buttons := make([]discordgo.MessageComponent, 0, len(candidates))
+	for i, name := range candidates {
+		lines = append(lines, fmt.Sprintf("%v %v", emojis[name], name))
+		label := []rune(name)
+		if len(label) > MAX_BUTTON_LABEL {
+			label = append(label[:MAX_BUTTON_LABEL-3], []rune("...")...)
+		}
+		buttons = append(buttons, discordgo.Button{
+			Label:    string(label),
+			Style:    discordgo.SecondaryButton,
+			Emoji:    &discordgo.ComponentEmoji{Name: emojis[name]},
+			CustomID: fmt.Sprintf(`{"type":"hidden-vote-%v"}`, i),
+		})
+	}
+	for len(buttons) > 0 {
+		n := min(BUTTONS_PER_ROW, len(buttons))
+		components = append(components, discordgo.ActionsRow{
+			Components: buttons[:n],
+		})
+		buttons = buttons[n:]
+	}
+	description := strings.Join(lines, "\n")
+	if pollInfo.ClosesAt != nil {
+		description += fmt.Sprintf("\n\nVotes are secret until voting closes <t:%v:R>. You can change your vote until then", pollInfo.ClosesAt.Unix())
+	}
+	embeds := []*discordgo.MessageEmbed{
+		{
+			Title:       componentPollTitle(pollInfo),
+			Description: description,
+			Color:       2326507,
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: fmt.Sprintf("%v votes cast", len(castBallots(pollInfo))),
+			},
+		},
+	}
+	return embeds, components
+}
+
+type HiddenVoteCommand struct {
+	GuildID     string
+	UserID      string
+	Choice      int
+	interaction *discordgo.Interaction
+}
+
+func NewHiddenVoteCommand(guildID, userID string, choice int, interaction *discordgo.Interaction) *HiddenVoteCommand {
+	return &HiddenVoteCommand{
+		GuildID:     guildID,
+		UserID:      userID,
+		Choice:      choice,
+		interaction: interaction,
+	}
+}
+
+func (c *HiddenVoteCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	s, err := cl.Discord()
+	if err != nil {
+		return nil, fmt.Errorf("discord: %v", err)
+	}
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	pollInfo, err := g.GetActivePoll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getActivePoll: %v", err)
+	}
+	if !isVotingOpen(pollInfo, c.interaction) || !pollInfo.Hidden {
+		err = replyEphemeral(s, c.interaction, "Voting on this poll has closed")
+		if err != nil {
+			return nil, fmt.Errorf("reply: %v", err)
+		}
+		components := []discordgo.MessageComponent{}
+		return &discordgo.WebhookEdit{
+			Components: &components,
+		}, nil
+	}
+
+	candidates := pollCandidates(pollInfo)
+	if c.Choice < 0 || c.Choice >= len(candidates) {
+		return nil, fmt.Errorf("invalid vote: %v", c.Choice)
+	}
+	choice := candidates[c.Choice]
+
+	ballot := []string{choice}
+	reply := fmt.Sprintf("You voted for %v. Press another entry to change your vote or the same one to withdraw it", choice)
+	if slices.Equal(pollInfo.Ballots[c.UserID], ballot) {
+		ballot = []string{}
+		reply = fmt.Sprintf("You withdrew your vote for %v", choice)
+	}
+	err = g.SetBallot(ctx, c.UserID, ballot)
+	if err != nil {
+		return nil, fmt.Errorf("setBallot: %v", err)
+	}
+	err = replyEphemeral(s, c.interaction, reply)
+	if err != nil {
+		return nil, fmt.Errorf("reply: %v", err)
+	}
+
+	embeds, components := buildHiddenPoll(pollInfo, nil)
+	return &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	}, nil
+}
+
+// hiddenPollResult counts the votes of a hidden poll
+func hiddenPollResult(ctx context.Context, pollInfo *guild.PollInfo) *pollResult {
+	voters := castBallots(pollInfo)
+	results := tallyVotes(voters)
+	winners := mostVoted(results, pollCandidates(pollInfo))
+	ctxzap.Info(ctx, fmt.Sprintf("Hidden poll results: %v", results))
+	return &pollResult{
+		entries:    pollInfo.Entries,
+		voters:     voters,
+		voterCount: len(voters),
+		winners:    winners,
+		tie:        len(winners) > 1,
+	}
+}
+
+// parseVoteIndex returns the entry index from a vote custom ID type
+func parseVoteIndex(customIDType, prefix string) (int, error) {
+	return strconv.Atoi(strings.TrimPrefix(customIDType, prefix))
+}
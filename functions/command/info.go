@@ -0,0 +1,160 @@
+package command
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+// Number of rating comments shown in /activity-info
+const MAX_INFO_COMMENTS int = 5
+
+const MAX_INFO_COMMENT_LENGTH int = 150
+
+type ActivityInfoCommand struct {
+	GuildID string
+	Name    string
+}
+
+func NewActivityInfoCommand(guildID, name string) *ActivityInfoCommand {
+	return &ActivityInfoCommand{
+		GuildID: guildID,
+		Name:    name,
+	}
+}
+
+func (c *ActivityInfoCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	act, err := activity.GetActivity(ctx, c.Name, c.GuildID, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			return utils.NewWebhookEdit(fmt.Sprintf("%v does not exist", c.Name)), nil
+		}
+		return nil, fmt.Errorf("getActivity: %v", err)
+	}
+
+	typ := "Activity"
+	if act.Typ() == activity.GAME {
+		typ = "Game"
+	}
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "Type",
+			Value:  typ,
+			Inline: true,
+		},
+		{
+			Name:   "Nominations",
+			Value:  fmt.Sprint(len(act.Nominations())),
+			Inline: true,
+		},
+	}
+	fowCount, lastFow := act.FowStats()
+	if lastFow != nil {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Flavor of the week",
+			Value:  fmt.Sprintf("%v times, last <t:%v:D>", fowCount, lastFow.Unix()),
+			Inline: true,
+		})
+	}
+	if players := activity.FormatPlayerCount(act.PlayerCount()); players != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Players",
+			Value:  players,
+			Inline: true,
+		})
+	}
+	if act.Typ() == activity.GAME {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Owned by",
+			Value:  fmt.Sprint(len(act.Owners())),
+			Inline: true,
+		})
+	}
+	if added := activity.FormatAdded(act.Added()); added != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   "Added",
+			Value:  added,
+			Inline: true,
+		})
+	}
+	description, links, _ := act.Details()
+	if len(links) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  "Links",
+			Value: strings.Join(links, "\n"),
+		})
+	}
+	if len(act.Aliases()) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  "Aliases",
+			Value: strings.Join(act.Aliases(), ", "),
+		})
+	}
+	if len(act.Tags()) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  "Tags",
+			Value: strings.Join(act.Tags(), ", "),
+		})
+	}
+	if rating := activity.FormatRating(act.Ratings()); rating != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  "Rating",
+			Value: rating,
+		})
+	}
+	if comments := ratingComments(act.Ratings()); len(comments) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  "Recent comments",
+			Value: strings.Join(comments, "\n"),
+		})
+	}
+
+	var thumbnail *discordgo.MessageEmbedThumbnail
+	if act.ImageURL() != "" {
+		thumbnail = &discordgo.MessageEmbedThumbnail{
+			URL: act.ImageURL(),
+		}
+	}
+	return &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{
+			{
+				Title:       act.Name(),
+				Description: description,
+				Color:       2326507,
+				Fields:      fields,
+				Thumbnail:   thumbnail,
+			},
+		},
+	}, nil
+}
+
+// ratingComments returns the newest rating comments
+func ratingComments(ratings map[string]activity.Rating) []string {
+	users := make([]string, 0, len(ratings))
+	for user, rating := range ratings {
+		if rating.Comment != "" {
+			users = append(users, user)
+		}
+	}
+	slices.SortFunc(users, func(a, b string) int {
+		return cmp.Or(ratings[b].RatedAt.Compare(ratings[a].RatedAt), cmp.Compare(a, b))
+	})
+	comments := make([]string, 0, MAX_INFO_COMMENTS)
+	for _, user := range users[:min(MAX_INFO_COMMENTS, len(users))] {
+		// Keep the field under the embed field limit
+		comment := []rune(ratings[user].Comment)
+		if len(comment) > MAX_INFO_COMMENT_LENGTH {
+			comment = append(comment[:MAX_INFO_COMMENT_LENGTH-1], '…')
+		}
+		comments = append(comments, fmt.Sprintf("%v/%v <@%v>: %v", ratings[user].Score, MAX_RATING, user, string(comment)))
+	}
+	return comments
+}
@@ -26,7 +26,7 @@ func NewNominationAddCommand(guildID, userID, name string) *NominationAddCommand
 }
 
 func (c *NominationAddCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
-	act, err := activity.GetActivity(ctx, c.Name, c.GuildID, cl)
+	act, err := activity.ResolveActivity(ctx, c.Name, c.GuildID, cl)
 	if err != nil {
 		ae, ok := err.(*activity.ActivityError)
 		if ok && ae.Reason == activity.DOES_NOT_EXIST {
@@ -38,7 +38,7 @@ func (c *NominationAddCommand) Execute(ctx context.Context, cl *clients.Clients)
 	if err != nil {
 		return nil, fmt.Errorf("act.AddNomination: %v", err)
 	}
-	return utils.NewWebhookEdit(fmt.Sprintf("Added a nomination for %v", c.Name)), nil
+	return utils.NewWebhookEdit(fmt.Sprintf("Added a nomination for %v", act.Name())), nil
 }
 
 type NominationRemoveCommand struct {
@@ -56,7 +56,7 @@ func NewNominationRemoveCommand(guildID, userID, name string) *NominationRemoveC
 }
 
 func (c *NominationRemoveCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
-	act, err := activity.GetActivity(ctx, c.Name, c.GuildID, cl)
+	act, err := activity.ResolveActivity(ctx, c.Name, c.GuildID, cl)
 	if err != nil {
 		ae, ok := err.(*activity.ActivityError)
 		if ok && ae.Reason == activity.DOES_NOT_EXIST {
@@ -68,7 +68,7 @@ func (c *NominationRemoveCommand) Execute(ctx context.Context, cl *clients.Clien
 	if err != nil {
 		return nil, fmt.Errorf("act.RemoveNomination: %v", err)
 	}
-	return utils.NewWebhookEdit(fmt.Sprintf("Removed a nomination for %v", c.Name)), nil
+	return utils.NewWebhookEdit(fmt.Sprintf("Removed a nomination for %v", act.Name())), nil
 }
 
 type NominationListCommand struct {
@@ -0,0 +1,149 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/customid"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	OWNERSHIP_OFF     = "off"
+	OWNERSHIP_PREFER  = "prefer"
+	OWNERSHIP_REQUIRE = "require"
+)
+
+type OwnCommand struct {
+	GuildID string
+	UserID  string
+	Name    string
+	Remove  bool
+}
+
+func NewOwnCommand(guildID, userID, name string, remove bool) *OwnCommand {
+	return &OwnCommand{
+		GuildID: guildID,
+		UserID:  userID,
+		Name:    name,
+		Remove:  remove,
+	}
+}
+
+func (c *OwnCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	act, err := activity.GetActivity(ctx, c.Name, c.GuildID, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			return utils.NewWebhookEdit(fmt.Sprintf("%v does not exist", c.Name)), nil
+		}
+		return nil, fmt.Errorf("getActivity: %v", err)
+	}
+	if act.Typ() != activity.GAME {
+		return utils.NewWebhookEdit(fmt.Sprintf("%v is not a game", act.Name())), nil
+	}
+	if c.Remove {
+		err = act.RemoveOwner(ctx, c.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("act.RemoveOwner: %v", err)
+		}
+		return utils.NewWebhookEdit(fmt.Sprintf("You no longer own %v", act.Name())), nil
+	}
+	err = act.AddOwner(ctx, c.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("act.AddOwner: %v", err)
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("You own %v. It is owned by %v members", act.Name(), len(act.Owners()))), nil
+}
+
+type OwnListCommand struct {
+	GuildID  string
+	UserID   string
+	CustomID *customid.CustomID
+}
+
+func NewOwnListCommand(guildID, userID string) *OwnListCommand {
+	return &OwnListCommand{
+		GuildID: guildID,
+		UserID:  userID,
+	}
+}
+
+func NewOwnListCommandFromCustomID(guildID, userID string, customID *customid.CustomID) *OwnListCommand {
+	return &OwnListCommand{
+		GuildID:  guildID,
+		UserID:   userID,
+		CustomID: customID,
+	}
+}
+
+func (c *OwnListCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	if c.CustomID == nil {
+		customID, err := customid.CreateCustomID(ctx, "own-list", customid.Filter{}, 0, cl)
+		if err != nil {
+			return nil, fmt.Errorf("CreateCustomID: %v", err)
+		}
+		c.CustomID = customID
+	}
+	entries, lastPage, err := activity.GetActivitiesPage(ctx, c.GuildID, c.CustomID.Page, &activity.ActivitesPageOptions{
+		OwnerId: c.UserID,
+	}, cl)
+	if err != nil {
+		return nil, fmt.Errorf("GetActivitesPage: %v", err)
+	}
+	edit := utils.BuildDiscordPage(entries, c.CustomID, &utils.PageOptions{IsLastPage: lastPage}, nil)
+	if edit.Embeds != nil && len(*edit.Embeds) == 0 {
+		return utils.NewWebhookEdit("You haven't marked any games as owned. Use `/own add`"), nil
+	}
+	return edit, nil
+}
+
+type OwnershipCommand struct {
+	GuildID   string
+	Mode      string
+	MinOwners int
+}
+
+func NewOwnershipCommand(guildID, mode string, minOwners int) *OwnershipCommand {
+	return &OwnershipCommand{
+		GuildID:   guildID,
+		Mode:      mode,
+		MinOwners: minOwners,
+	}
+}
+
+func (c *OwnershipCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	switch c.Mode {
+	case OWNERSHIP_OFF:
+		err = g.ClearOwnership(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("clearOwnership: %v", err)
+		}
+		return utils.NewWebhookEdit("Game ownership will not affect polls"), nil
+	case OWNERSHIP_PREFER, OWNERSHIP_REQUIRE:
+	default:
+		return utils.NewWebhookEdit(fmt.Sprintf("Unknown ownership mode: %v", c.Mode)), nil
+	}
+	if c.MinOwners < 1 {
+		return utils.NewWebhookEdit("The minimum number of owners must be at least 1"), nil
+	}
+	err = g.SetOwnership(ctx, &guild.OwnershipInfo{
+		MinOwners: c.MinOwners,
+		Required:  c.Mode == OWNERSHIP_REQUIRE,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("setOwnership: %v", err)
+	}
+	if c.Mode == OWNERSHIP_REQUIRE {
+		return utils.NewWebhookEdit(fmt.Sprintf("Polls will only include games owned by at least %v members", c.MinOwners)), nil
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Polls will prefer games owned by at least %v members", c.MinOwners)), nil
+}
@@ -0,0 +1,109 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/dimuska139/rawg-sdk-go/v3"
+)
+
+// RAWG tags that mean a game can be played with others
+var multiplayerTags = []string{"multiplayer", "online-multiplayer", "local-multiplayer", "co-op", "online-co-op", "local-co-op", "split-screen", "pvp", "online-pvp"}
+
+// rawgPlayerCount estimates the player count of a game from its RAWG tags. 0 means unknown.
+func rawgPlayerCount(detail *rawg.GameDetailed) (int, int) {
+	single, multi := false, false
+	for _, tag := range detail.Tags {
+		if tag == nil {
+			continue
+		}
+		if tag.Slug == "singleplayer" {
+			single = true
+		}
+		for _, slug := range multiplayerTags {
+			if tag.Slug == slug {
+				multi = true
+			}
+		}
+	}
+	switch {
+	case single && !multi:
+		return 1, 1
+	case single:
+		return 1, 0
+	case multi:
+		return 2, 0
+	}
+	return 0, 0
+}
+
+type PlayerCountCommand struct {
+	GuildID    string
+	Name       string
+	MinPlayers int
+	MaxPlayers int
+}
+
+func NewPlayerCountCommand(guildID, name string, minPlayers, maxPlayers int) *PlayerCountCommand {
+	return &PlayerCountCommand{
+		GuildID:    guildID,
+		Name:       name,
+		MinPlayers: minPlayers,
+		MaxPlayers: maxPlayers,
+	}
+}
+
+func (c *PlayerCountCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	if c.MinPlayers < 0 || c.MaxPlayers < 0 || (c.MaxPlayers != 0 && c.MaxPlayers < c.MinPlayers) {
+		return utils.NewWebhookEdit("The max players can't be less than the min players"), nil
+	}
+	act, err := activity.GetActivity(ctx, c.Name, c.GuildID, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			return utils.NewWebhookEdit(fmt.Sprintf("%v does not exist", c.Name)), nil
+		}
+		return nil, fmt.Errorf("getActivity: %v", err)
+	}
+	err = act.SetPlayerCount(ctx, c.MinPlayers, c.MaxPlayers)
+	if err != nil {
+		return nil, fmt.Errorf("setPlayerCount: %v", err)
+	}
+	players := activity.FormatPlayerCount(c.MinPlayers, c.MaxPlayers)
+	if players == "" {
+		return utils.NewWebhookEdit(fmt.Sprintf("Cleared the player count of %v", act.Name())), nil
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("%v is for %v players", act.Name(), players)), nil
+}
+
+type ExpectedPlayersCommand struct {
+	GuildID string
+	Players int
+}
+
+func NewExpectedPlayersCommand(guildID string, players int) *ExpectedPlayersCommand {
+	return &ExpectedPlayersCommand{
+		GuildID: guildID,
+		Players: players,
+	}
+}
+
+func (c *ExpectedPlayersCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	err = g.SetExpectedPlayers(ctx, max(c.Players, 0))
+	if err != nil {
+		return nil, fmt.Errorf("setExpectedPlayers: %v", err)
+	}
+	if c.Players <= 0 {
+		return utils.NewWebhookEdit("The next poll will include activities for any number of players"), nil
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("The next poll will only include activities that can be played by %v players", c.Players)), nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"slices"
+	"strconv"
 	"time"
 
 	"math/rand"
@@ -22,18 +23,28 @@ import (
 
 const MAX_POLL_ENTRIES int = 7
 
+// Max characters in a poll answer
+const MAX_ANSWER_LENGTH int = 55
+
+const REROLL_ANSWER string = "Reroll"
+
 type CreatePollCommand struct {
 	GuildID             string
-	Options             []discordgo.PollAnswer
+	Entries             []guild.PollEntry
 	Duration            int
 	SuddenDeath         bool
 	skipActivePollCheck bool
+	extension           bool
+	carriedVotes        map[string][]string
+	bracketRound        int
+	// Activity whose session time is being polled
+	timePoll string
 }
 
-func NewCreatePollCommand(guildID string, options []discordgo.PollAnswer, duration int, suddenDeath bool) *CreatePollCommand {
+func NewCreatePollCommand(guildID string, entries []guild.PollEntry, duration int, suddenDeath bool) *CreatePollCommand {
 	return &CreatePollCommand{
 		GuildID:     guildID,
-		Options:     options,
+		Entries:     entries,
 		Duration:    duration,
 		SuddenDeath: suddenDeath,
 	}
@@ -43,6 +54,23 @@ func (c *CreatePollCommand) SkipActivePollCheck(skip bool) {
 	c.skipActivePollCheck = skip
 }
 
+// SetBracketRound marks the poll as a match in the given round of a tournament
+func (c *CreatePollCommand) SetBracketRound(round int) {
+	c.bracketRound = round
+}
+
+// SetTimePoll makes the poll a vote on when to play the activity
+func (c *CreatePollCommand) SetTimePoll(activity string) {
+	c.timePoll = activity
+}
+
+// SetExtension marks the poll as continuing one that missed quorum. The votes of that poll
+// still count unless the voter votes again
+func (c *CreatePollCommand) SetExtension(carriedVotes map[string][]string) {
+	c.extension = true
+	c.carriedVotes = carriedVotes
+}
+
 func (c *CreatePollCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
 	s, err := cl.Discord()
 	if err != nil {
@@ -63,27 +91,82 @@ func (c *CreatePollCommand) Execute(ctx context.Context, cl *clients.Clients) (*
 	if err != nil {
 		return nil, fmt.Errorf("getActivePollID: %v", err)
 	}
+	if c.timePoll == "" {
+		pollID, err = closeTimePoll(ctx, c.GuildID, pollID, cl)
+		if err != nil {
+			return nil, fmt.Errorf("closeTimePoll: %v", err)
+		}
+	}
 	if !c.skipActivePollCheck && pollID != nil {
 		return utils.NewWebhookEdit("There is already an active poll"), nil
 	}
-	if c.Options == nil {
-		c.Options, err = GeneratePollEntries(ctx, g, cl)
+	if c.Entries == nil {
+		c.Entries, err = GeneratePollEntries(ctx, g, cl)
 		if err != nil {
 			return nil, fmt.Errorf("generatePollEntries: %v", err)
 		}
 	}
+	mode, err := g.GetVotingMode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getVotingMode: %v", err)
+	}
+	var pollInfo *guild.PollInfo
+	if mode != guild.VOTING_NATIVE && !c.SuddenDeath && c.timePoll == "" {
+		pollInfo, err = c.sendComponentPoll(s, *chanID, mode)
+		if err != nil {
+			return nil, fmt.Errorf("sendComponentPoll: %v", err)
+		}
+	} else {
+		pollInfo, err = c.sendNativePoll(s, *chanID)
+		if err != nil {
+			return nil, fmt.Errorf("sendNativePoll: %v", err)
+		}
+	}
+	pollInfo.Extended = c.extension
+	pollInfo.CarriedVotes = c.carriedVotes
+	err = g.SetActivePoll(ctx, pollInfo)
+	if err != nil {
+		return nil, fmt.Errorf("setActivePoll: %v", err)
+	}
+	if !c.SuddenDeath && !c.extension && c.bracketRound == 0 && c.timePoll == "" {
+		err = trackLeftOutNominators(ctx, g, c.Entries, cl)
+		if err != nil {
+			// The poll is already posted
+			ctxzap.Warn(ctx, fmt.Sprintf("trackLeftOutNominators: %v", err))
+		}
+	}
+	msgLink := fmt.Sprintf("https://discord.com/channels/%v/%v/%v", c.GuildID, *chanID, pollInfo.MessageID)
+	return utils.NewWebhookEdit(fmt.Sprintf("Poll created: %v", msgLink)), nil
+}
+
+func (c *CreatePollCommand) sendNativePoll(s *discordgo.Session, chanID string) (*guild.PollInfo, error) {
+	answers := pollEntriesToAnswers(c.Entries)
+	if !c.SuddenDeath && c.bracketRound == 0 && c.timePoll == "" {
+		answers = append(answers, discordgo.PollAnswer{
+			Media: &discordgo.PollMedia{
+				Text: REROLL_ANSWER,
+				Emoji: &discordgo.ComponentEmoji{
+					Name: "🎲",
+				},
+			},
+		})
+	}
 
 	text := "What should the flavor of the week be?"
 	if c.SuddenDeath {
 		text = "Sudden Death Tie Breaker"
+	} else if c.bracketRound > 0 {
+		text = fmt.Sprintf("🏆 Tournament round %v", c.bracketRound)
+	} else if c.timePoll != "" {
+		text = fmt.Sprintf("When should we play %v?", truncateActivityName(c.timePoll))
 	}
 
-	msg, err := s.ChannelMessageSendComplex(*chanID, &discordgo.MessageSend{
+	msg, err := s.ChannelMessageSendComplex(chanID, &discordgo.MessageSend{
 		Poll: &discordgo.Poll{
 			Question: discordgo.PollMedia{
 				Text: text,
 			},
-			Answers:          c.Options,
+			Answers:          answers,
 			AllowMultiselect: true,
 			LayoutType:       discordgo.PollLayoutTypeDefault,
 			Duration:         c.Duration,
@@ -92,16 +175,55 @@ func (c *CreatePollCommand) Execute(ctx context.Context, cl *clients.Clients) (*
 	if err != nil {
 		return nil, fmt.Errorf("channelMessageSendComplex: %v", err)
 	}
-	err = g.SetActivePoll(ctx, &guild.PollInfo{
-		ChannelID:   *chanID,
+	// Remember which activity each answer is for so truncated names don't need to be recovered
+	answerActivities := make(map[string]string)
+	if msg.Poll != nil && c.timePoll == "" {
+		for i, entry := range c.Entries {
+			if i >= len(msg.Poll.Answers) {
+				break
+			}
+			if entry.Name == REROLL_ANSWER {
+				continue
+			}
+			answerActivities[strconv.Itoa(msg.Poll.Answers[i].AnswerID)] = activity.GetActivityID(c.GuildID, entry.Name)
+		}
+	}
+	return &guild.PollInfo{
+		ChannelID:   chanID,
 		MessageID:   msg.ID,
 		SuddenDeath: c.SuddenDeath,
+		Answers:     answerActivities,
+		Bracket:     c.bracketRound > 0,
+		TimePoll:    c.timePoll != "",
+	}, nil
+}
+
+func (c *CreatePollCommand) sendComponentPoll(s *discordgo.Session, chanID, mode string) (*guild.PollInfo, error) {
+	closesAt := time.Now().UTC().Add(time.Duration(c.Duration) * time.Hour)
+	pollInfo := &guild.PollInfo{
+		ChannelID: chanID,
+		Ranked:    mode == guild.VOTING_RANKED,
+		Hidden:    mode == guild.VOTING_HIDDEN,
+		Entries:   c.Entries,
+		ClosesAt:  &closesAt,
+		Bracket:   c.bracketRound > 0,
+	}
+	var embeds []*discordgo.MessageEmbed
+	var components []discordgo.MessageComponent
+	if pollInfo.Ranked {
+		embeds, components = buildRankedPoll(pollInfo)
+	} else {
+		embeds, components = buildHiddenPoll(pollInfo, nil)
+	}
+	msg, err := s.ChannelMessageSendComplex(chanID, &discordgo.MessageSend{
+		Embeds:     embeds,
+		Components: components,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("setActivePoll: %v", err)
+		return nil, fmt.Errorf("channelMessageSendComplex: %v", err)
 	}
-	msgLink := fmt.Sprintf("https://discord.com/channels/%v/%v/%v", c.GuildID, *chanID, msg.ID)
-	return utils.NewWebhookEdit(fmt.Sprintf("Poll created: %v", msgLink)), nil
+	pollInfo.MessageID = msg.ID
+	return pollInfo, nil
 }
 
 type StartPollCommand struct {
@@ -131,108 +253,91 @@ func (c *StartPollCommand) Execute(ctx context.Context, cl *clients.Clients) (*d
 
 }
 
-func GeneratePollEntries(ctx context.Context, guild *guild.Guild, cl *clients.Clients) ([]discordgo.PollAnswer, error) {
-	type answerEntry struct {
-		count int
-		emoji string
-	}
+const (
+	PINNED_EMOJI     = "📌"
+	NOMINATION_EMOJI = "🗳️"
+	RANDOM_EMOJI     = "🎰"
+)
+
+type answerEntry struct {
+	emoji  string
+	source string
+	locked bool
+}
 
+func GeneratePollEntries(ctx context.Context, guild *guild.Guild, cl *clients.Clients) ([]guild.PollEntry, error) {
+	return generatePollEntries(ctx, guild, nil, cl)
+}
+
+// generatePollEntries fills a poll starting from the given entries. The seed
+// entries are kept in place and only the remaining slots are filled.
+func generatePollEntries(ctx context.Context, g *guild.Guild, seed []guild.PollEntry, cl *clients.Clients) ([]guild.PollEntry, error) {
 	answers := orderedmap.NewOrderedMap[string, answerEntry]()
+	for _, entry := range seed {
+		answers.Set(entry.Name, answerEntry{
+			emoji:  entry.Emoji,
+			source: entry.Source,
+			locked: entry.Locked,
+		})
+	}
 
-	fow, err := guild.GetFow(ctx)
+	composition, err := g.GetPollComposition(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("getFow: %v", err)
+		return nil, fmt.Errorf("getPollComposition: %v", err)
 	}
-	if fow != nil {
-		answers.Set(*fow, answerEntry{
-			count: 1,
-			emoji: "📌",
-		})
+	err = composePoll(ctx, g, composition, MAX_POLL_ENTRIES, answers, cl)
+	if err != nil {
+		return nil, fmt.Errorf("composePoll: %v", err)
 	}
-
-	ctxzap.Info(ctx, "Getting top nominations")
-	// Add top nominations. Add one in case the current FOW is a top nomination
-	nominations, err := activity.GetTopNominations(ctx, guild.GetGuildId(), MAX_POLL_ENTRIES-answers.Len()+1, cl)
+	err = applyActivityEmojis(ctx, g, answers, cl)
 	if err != nil {
-		return nil, fmt.Errorf("getTopNominations: %v", err)
+		return nil, fmt.Errorf("applyActivityEmojis: %v", err)
 	}
+	ctxzap.Info(ctx, fmt.Sprintf("Generated poll entries: %v", answers))
+	return answersToPollEntries(answers), nil
+}
 
-	for _, nom := range nominations {
-		tmp := answers.GetOrDefault(nom, answerEntry{
-			count: 0,
-			emoji: "🗳️",
+func answersToPollEntries(answers *orderedmap.OrderedMap[string, answerEntry]) []guild.PollEntry {
+	entries := make([]guild.PollEntry, 0, answers.Len())
+	for el := answers.Front(); el != nil; el = el.Next() {
+		entries = append(entries, guild.PollEntry{
+			Name:   el.Key,
+			Emoji:  el.Value.emoji,
+			Source: el.Value.source,
+			Locked: el.Value.locked,
 		})
-		tmp.count += 1
-		answers.Set(nom,
-			tmp,
-		)
-		if answers.Len() == MAX_POLL_ENTRIES {
-			break
-		}
-	}
-	// Now pick random entries
-	loop_count := 0
-out:
-	for answers.Len() < MAX_POLL_ENTRIES && loop_count < 5 {
-		ctxzap.Info(ctx, fmt.Sprintf("Getting random activities nominations. Try %v", loop_count))
-		randomsChoices, err := activity.GetRandomActivities(ctx, guild.GetGuildId(), MAX_POLL_ENTRIES-answers.Len(), cl)
-		if err != nil {
-			return nil, fmt.Errorf("getRandomActivities: %v", err)
-		}
-		for _, choice := range randomsChoices {
-			tmp := answers.GetOrDefault(choice, answerEntry{
-				count: 0,
-				emoji: "🎰",
-			})
-			tmp.count += 1
-			answers.Set(choice,
-				tmp,
-			)
-		}
-
-		// Check if we are repeating which is indicative of not enough answers in the pool to fill a poll
-		for el := answers.Back(); el != nil; el = el.Prev() {
-			if el.Value.count > 5 {
-				break out
-			}
-		}
-		loop_count += 1
 	}
+	return entries
+}
 
-	ctxzap.Info(ctx, fmt.Sprintf("Generated poll entries: %v", answers))
-	results := make([]discordgo.PollAnswer, 0, answers.Len())
-	for el := answers.Front(); el != nil; el = el.Next() {
+func pollEntriesToAnswers(entries []guild.PollEntry) []discordgo.PollAnswer {
+	results := make([]discordgo.PollAnswer, 0, len(entries))
+	for _, entry := range entries {
 		results = append(results, discordgo.PollAnswer{
 			Media: &discordgo.PollMedia{
-				Text: truncateActivityName(el.Key),
+				Text: truncateActivityName(entry.Name),
 				Emoji: &discordgo.ComponentEmoji{
-					Name: el.Value.emoji,
+					Name: entry.Emoji,
 				},
 			},
 		})
 	}
-	results = append(results, discordgo.PollAnswer{
-		Media: &discordgo.PollMedia{
-			Text: "Reroll",
-			Emoji: &discordgo.ComponentEmoji{
-				Name: "🎲",
-			},
-		},
-	})
-	return results, nil
+	return results
 }
 
 func truncateActivityName(name string) string {
-	if len(name) > 55 {
-		return fmt.Sprintf("%v...", name[:52])
+	runes := []rune(name)
+	if len(runes) > MAX_ANSWER_LENGTH {
+		return fmt.Sprintf("%v...", string(runes[:MAX_ANSWER_LENGTH-3]))
 	}
 	return name
 }
 
 func recoverTruncatedActivity(ctx context.Context, name, guildID string, cl *clients.Clients) (string, error) {
-	if len(name) == 55 && name[52:] == "..." {
+	runes := []rune(name)
+	if len(runes) == MAX_ANSWER_LENGTH && string(runes[MAX_ANSWER_LENGTH-3:]) == "..." {
 		// Name may be truncated
-		fullName, err := activity.RecoverActivity(ctx, guildID, name[:52], cl)
+		fullName, err := activity.RecoverActivity(ctx, guildID, string(runes[:MAX_ANSWER_LENGTH-3]), cl)
 		if err != nil {
 			return "", fmt.Errorf("recoverActivity: %v", err)
 		}
@@ -269,52 +374,55 @@ func (c *EndPollCommand) Execute(ctx context.Context, cl *clients.Clients) (*dis
 	if err != nil {
 		return nil, fmt.Errorf("discord: %v", err)
 	}
-	// Get the poll status
-	msg, err := s.ChannelMessage(pollID.ChannelID, pollID.MessageID)
-	if err != nil {
-		if restErr, ok := err.(*discordgo.RESTError); ok && restErr.Response.StatusCode == http.StatusNotFound {
-			// If the poll has been deleted, reset the poll status
-			err := g.ClearActivePoll(ctx)
-			if err != nil {
-				return nil, fmt.Errorf("clearActivePoll: %v", err)
-			}
+	var result *pollResult
+	if pollID.Ranked {
+		result = rankedPollResult(ctx, pollID)
+	} else if pollID.Hidden {
+		result = hiddenPollResult(ctx, pollID)
+	} else {
+		var response *discordgo.WebhookEdit
+		result, response, err = c.nativePollResult(ctx, s, g, pollID, cl)
+		if result == nil {
+			return response, err
 		}
-		return utils.NewWebhookEdit("⚠️ Unable to retrieve the poll"), fmt.Errorf("channelMessage: %v", err)
-	}
-	if msg.Poll == nil {
-		return utils.NewWebhookEdit("⚠️ No poll associated with the message"), fmt.Errorf("missing poll")
 	}
-	if msg.Poll.Results == nil || !msg.Poll.Results.Finalized || msg.Poll.Results.AnswerCounts == nil {
-		msg, err = s.PollExpire(pollID.ChannelID, pollID.MessageID)
+	if !pollID.SuddenDeath && !pollID.Bracket && !pollID.TimePoll {
+		met, required, err := checkQuorum(ctx, s, g, result.voterCount)
 		if err != nil {
-			return utils.NewWebhookEdit("⚠️ Unable to end the poll"), fmt.Errorf("pollExpire: %v", err)
+			return nil, fmt.Errorf("checkQuorum: %v", err)
 		}
-		waitForResults := func() error {
-			msg, err = s.ChannelMessage(pollID.ChannelID, pollID.MessageID)
-			if err != nil || msg.Poll == nil {
-				return fmt.Errorf("channelMessage: %v", err)
-			}
-			if msg.Poll.Results == nil || !msg.Poll.Results.Finalized || msg.Poll.Results.AnswerCounts == nil {
-				return fmt.Errorf("poll not finalized")
+		if !met {
+			err = recordPollVotes(ctx, g, pollID, result.voters, result.voterCount, nil, cl)
+			if err != nil {
+				ctxzap.Warn(ctx, fmt.Sprintf("recordPollVotes: %v", err))
 			}
-			return nil
+			return handleMissedQuorum(ctx, s, g, pollID, result, required, cl)
 		}
-		err = backoff.Retry(waitForResults, backoff.NewExponentialBackOff(backoff.WithInitialInterval(time.Millisecond*750), backoff.WithMaxElapsedTime(time.Second*30)))
+	}
+	if pollID.IsComponentPoll() {
+		err = closeComponentPoll(s, pollID)
 		if err != nil {
-			return utils.NewWebhookEdit("Failed to end the poll"), fmt.Errorf("waitForResults: %v", err)
-		}
-		if msg.Poll.Results == nil || !msg.Poll.Results.Finalized || msg.Poll.Results.AnswerCounts == nil {
-			return utils.NewWebhookEdit("Failed to get the poll results"), nil
+			return nil, fmt.Errorf("closeComponentPoll: %v", err)
 		}
 	}
-	// Poll has ended, get the results
-	winners, tie := determinePollWinners(msg.Poll)
+	if pollID.Bracket {
+		return advanceBracket(ctx, s, g, pollID, result, cl)
+	}
+	if pollID.TimePoll {
+		return endTimePoll(ctx, s, g, pollID, result, cl)
+	}
+	winners, tie := result.winners, result.tie
+	err = recordPollVotes(ctx, g, pollID, result.voters, result.voterCount, winners, cl)
+	if err != nil {
+		ctxzap.Warn(ctx, fmt.Sprintf("recordPollVotes: %v", err))
+	}
 	var response *discordgo.WebhookEdit
+	var fow string
 	if tie {
 		if pollID.SuddenDeath {
 			// If it is a sudden death poll, choose at random
 			winner := winners[rand.Intn(len(winners))]
-			if winner == "Reroll" {
+			if winner == REROLL_ANSWER {
 				// Create a new poll
 				pollCmd := NewStartPollCommand(c.GuildID)
 				pollCmd.SkipActivePollCheck(true)
@@ -336,26 +444,23 @@ func (c *EndPollCommand) Execute(ctx context.Context, cl *clients.Clients) (*dis
 			if err != nil {
 				return nil, fmt.Errorf("declareWinner: %v", err)
 			}
+			fow = winner
 			response = utils.NewWebhookEdit(fmt.Sprintf("Poll ended\nWinner: %v", winner))
 
 		} else {
 			// Start a sudden death poll
-			pollWinners := make([]discordgo.PollAnswer, 0)
+			pollWinners := make([]guild.PollEntry, 0)
 			for _, ans := range winners {
-				pollWinners = append(pollWinners, discordgo.PollAnswer{
-					Media: &discordgo.PollMedia{
-						Text: ans,
-						Emoji: &discordgo.ComponentEmoji{
-							Name: "⚡",
-						},
-					},
+				pollWinners = append(pollWinners, guild.PollEntry{
+					Name:  ans,
+					Emoji: "⚡",
 				})
 			}
 			pollCmd := NewCreatePollCommand(c.GuildID, pollWinners, 2, true)
 			pollCmd.SkipActivePollCheck(true)
 			return pollCmd.Execute(ctx, cl)
 		}
-	} else if winners[0] == "Reroll" {
+	} else if winners[0] == REROLL_ANSWER {
 		// Create a new poll
 		pollCmd := NewStartPollCommand(c.GuildID)
 		pollCmd.SkipActivePollCheck(true)
@@ -365,6 +470,7 @@ func (c *EndPollCommand) Execute(ctx context.Context, cl *clients.Clients) (*dis
 		if err != nil {
 			return nil, fmt.Errorf("declareWinner: %v", err)
 		}
+		fow = winners[0]
 		response = utils.NewWebhookEdit(fmt.Sprintf("Poll ended\nWinner: %v", winners[0]))
 	}
 	err = g.ClearActivePoll(ctx)
@@ -375,28 +481,219 @@ func (c *EndPollCommand) Execute(ctx context.Context, cl *clients.Clients) (*dis
 	if err != nil {
 		return nil, fmt.Errorf("clearNominations: %v", err)
 	}
+	err = startTimePoll(ctx, g, fow, cl)
+	if err != nil {
+		return nil, fmt.Errorf("startTimePoll: %v", err)
+	}
 	return response, nil
 }
 
-func declareWinner(ctx context.Context, winner, guildID string, g *guild.Guild, cl *clients.Clients) error {
-	// Recover truncated name
-	winner, err := recoverTruncatedActivity(ctx, winner, guildID, cl)
+type pollResult struct {
+	// Entries of the poll excluding the reroll answer
+	entries []guild.PollEntry
+	// User ID to the names they voted for
+	voters     map[string][]string
+	voterCount int
+	winners    []string
+	tie        bool
+}
+
+// nativePollResult ends a Discord poll and reads its results. When the results
+// can't be read the response to send is returned instead.
+func (c *EndPollCommand) nativePollResult(ctx context.Context, s *discordgo.Session, g *guild.Guild, pollID *guild.PollInfo, cl *clients.Clients) (*pollResult, *discordgo.WebhookEdit, error) {
+	// Get the poll status
+	msg, err := s.ChannelMessage(pollID.ChannelID, pollID.MessageID)
 	if err != nil {
-		return fmt.Errorf("recoverTruncatedActivity: %v", err)
+		if restErr, ok := err.(*discordgo.RESTError); ok && restErr.Response.StatusCode == http.StatusNotFound {
+			// If the poll has been deleted, reset the poll status
+			err := g.ClearActivePoll(ctx)
+			if err != nil {
+				return nil, nil, fmt.Errorf("clearActivePoll: %v", err)
+			}
+		}
+		return nil, utils.NewWebhookEdit("⚠️ Unable to retrieve the poll"), fmt.Errorf("channelMessage: %v", err)
 	}
-	err = g.SetFow(ctx, winner)
+	if msg.Poll == nil {
+		return nil, utils.NewWebhookEdit("⚠️ No poll associated with the message"), fmt.Errorf("missing poll")
+	}
+	if msg.Poll.Results == nil || !msg.Poll.Results.Finalized || msg.Poll.Results.AnswerCounts == nil {
+		msg, err = s.PollExpire(pollID.ChannelID, pollID.MessageID)
+		if err != nil {
+			return nil, utils.NewWebhookEdit("⚠️ Unable to end the poll"), fmt.Errorf("pollExpire: %v", err)
+		}
+		waitForResults := func() error {
+			msg, err = s.ChannelMessage(pollID.ChannelID, pollID.MessageID)
+			if err != nil || msg.Poll == nil {
+				return fmt.Errorf("channelMessage: %v", err)
+			}
+			if msg.Poll.Results == nil || !msg.Poll.Results.Finalized || msg.Poll.Results.AnswerCounts == nil {
+				return fmt.Errorf("poll not finalized")
+			}
+			return nil
+		}
+		err = backoff.Retry(waitForResults, backoff.NewExponentialBackOff(backoff.WithInitialInterval(time.Millisecond*750), backoff.WithMaxElapsedTime(time.Second*30)))
+		if err != nil {
+			return nil, utils.NewWebhookEdit("Failed to end the poll"), fmt.Errorf("waitForResults: %v", err)
+		}
+		if msg.Poll.Results == nil || !msg.Poll.Results.Finalized || msg.Poll.Results.AnswerCounts == nil {
+			return nil, utils.NewWebhookEdit("Failed to get the poll results"), nil
+		}
+	}
+	// Poll has ended, get the results
+	names, err := resolvePollAnswers(ctx, pollID, msg.Poll.Answers, c.GuildID, cl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolvePollAnswers: %v", err)
+	}
+	answerVoters, err := getPollVoters(s, pollID.ChannelID, pollID.MessageID, msg.Poll)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getPollVoters: %v", err)
+	}
+	result := &pollResult{
+		entries:    make([]guild.PollEntry, 0, len(names)),
+		voters:     make(map[string][]string),
+		voterCount: countVoters(answerVoters),
+	}
+	answerNames := make(map[int]string)
+	for i, ans := range msg.Poll.Answers {
+		answerNames[ans.AnswerID] = names[i]
+		for _, user := range answerVoters[ans.AnswerID] {
+			result.voters[user] = append(result.voters[user], names[i])
+		}
+		if names[i] == REROLL_ANSWER {
+			continue
+		}
+		entry := guild.PollEntry{Name: names[i]}
+		if ans.Media != nil && ans.Media.Emoji != nil {
+			entry.Emoji = ans.Media.Emoji.Name
+		}
+		result.entries = append(result.entries, entry)
+	}
+	if len(pollID.CarriedVotes) > 0 {
+		// Voters of the poll this extends keep their votes unless they voted again
+		for user, votedFor := range pollID.CarriedVotes {
+			if _, ok := result.voters[user]; !ok {
+				result.voters[user] = votedFor
+			}
+		}
+		result.voterCount = len(result.voters)
+		result.winners = mostVoted(tallyVotes(result.voters), names)
+		result.tie = len(result.winners) > 1
+		return result, nil, nil
+	}
+	winningAnswers, tie := determinePollWinners(msg.Poll)
+	for _, ans := range winningAnswers {
+		result.winners = append(result.winners, answerNames[ans.AnswerID])
+	}
+	result.tie = tie
+	return result, nil, nil
+}
+
+// resolvePollAnswers maps poll answers back to the full activity names
+func resolvePollAnswers(ctx context.Context, pollInfo *guild.PollInfo, answers []discordgo.PollAnswer, guildID string, cl *clients.Clients) ([]string, error) {
+	names := make([]string, 0, len(answers))
+	for _, ans := range answers {
+		if activityID, ok := pollInfo.Answers[strconv.Itoa(ans.AnswerID)]; ok {
+			act, err := activity.GetActivityByID(ctx, activityID, cl)
+			if err == nil {
+				names = append(names, act.Name())
+				continue
+			}
+			ae, ok := err.(*activity.ActivityError)
+			if !ok || ae.Reason != activity.DOES_NOT_EXIST {
+				return nil, fmt.Errorf("getActivityByID: %v", err)
+			}
+			ctxzap.Warn(ctx, fmt.Sprintf("Activity %v for answer %v no longer exists", activityID, ans.AnswerID))
+		}
+		if ans.Media == nil {
+			return nil, fmt.Errorf("missing media for answer %v", ans.AnswerID)
+		}
+		if ans.Media.Text == REROLL_ANSWER {
+			names = append(names, REROLL_ANSWER)
+			continue
+		}
+		// Polls created before answers were tracked only have the truncated name
+		name, err := recoverTruncatedActivity(ctx, ans.Media.Text, guildID, cl)
+		if err != nil {
+			return nil, fmt.Errorf("recoverTruncatedActivity: %v", err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// closeComponentPoll shows a ranked or hidden poll as closed, revealing the votes of hidden polls
+func closeComponentPoll(s *discordgo.Session, pollInfo *guild.PollInfo) error {
+	// Show the poll as closed even if it ended early
+	now := time.Now()
+	pollInfo.ClosesAt = &now
+	var embeds []*discordgo.MessageEmbed
+	var components []discordgo.MessageComponent
+	if pollInfo.Ranked {
+		embeds, components = buildRankedPoll(pollInfo)
+	} else {
+		embeds, components = buildHiddenPoll(pollInfo, tallyVotes(castBallots(pollInfo)))
+	}
+	_, err := s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    pollInfo.ChannelID,
+		ID:         pollInfo.MessageID,
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	if err != nil {
+		return fmt.Errorf("channelMessageEditComplex: %v", err)
+	}
+	return nil
+}
+
+func declareWinner(ctx context.Context, winner, guildID string, g *guild.Guild, cl *clients.Clients) error {
+	err := g.SetFow(ctx, winner)
 	if err != nil {
 		return fmt.Errorf("SetFow: %v", err)
 	}
+	err = markFow(ctx, winner, guildID, cl)
+	if err != nil {
+		return fmt.Errorf("markFow: %v", err)
+	}
+	err = syncSessionEvent(ctx, g, winner, cl)
+	if err != nil {
+		// The winner stands even if the event couldn't be created
+		ctxzap.Error(ctx, fmt.Sprintf("syncSessionEvent: %v", err))
+	}
+	// Must run before postRsvp records the new flavor of the week
+	err = postRatingPrompt(ctx, g, cl)
+	if err != nil {
+		ctxzap.Error(ctx, fmt.Sprintf("postRatingPrompt: %v", err))
+	}
+	err = postRsvp(ctx, g, winner, cl)
+	if err != nil {
+		// Failing here would end the poll again and count the win twice
+		ctxzap.Error(ctx, fmt.Sprintf("postRsvp: %v", err))
+	}
+	return nil
+}
+
+func markFow(ctx context.Context, name, guildID string, cl *clients.Clients) error {
+	act, err := activity.GetActivity(ctx, name, guildID, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			return nil
+		}
+		return fmt.Errorf("getActivity: %v", err)
+	}
+	err = act.MarkFow(ctx)
+	if err != nil {
+		return fmt.Errorf("markFow: %v", err)
+	}
 	return nil
 
 }
 
-func determinePollWinners(poll *discordgo.Poll) ([]string, bool) {
+func determinePollWinners(poll *discordgo.Poll) ([]discordgo.PollAnswer, bool) {
 	answerCounts := poll.Results.AnswerCounts
-	// There are no votes
+	// There are no votes so every answer is tied
 	if len(answerCounts) == 0 {
-		return nil, true
+		return slices.Clone(poll.Answers), len(poll.Answers) > 1
 	}
 
 	// sort results
@@ -412,13 +709,13 @@ func determinePollWinners(poll *discordgo.Poll) ([]string, bool) {
 	})
 	maxVote := answerCounts[0].Count
 
-	winners := make([]string, 0)
+	winners := make([]discordgo.PollAnswer, 0)
 	for _, ans := range answerCounts {
 		if ans.Count == maxVote {
 			i := slices.IndexFunc(poll.Answers, func(a discordgo.PollAnswer) bool {
 				return a.AnswerID == ans.ID
 			})
-			winners = append(winners, poll.Answers[i].Media.Text)
+			winners = append(winners, poll.Answers[i])
 		} else {
 			break
 		}
@@ -450,6 +747,16 @@ func (c *SetPollChannelCommand) Execute(ctx context.Context, cl *clients.Clients
 	return utils.NewWebhookEdit(fmt.Sprintf("Set poll channel to <#%v>", c.ChannelID)), nil
 }
 
+var dayLookup = map[string]time.Weekday{
+	"Sunday":    time.Sunday,
+	"Monday":    time.Monday,
+	"Tuesday":   time.Tuesday,
+	"Wednesday": time.Wednesday,
+	"Thursday":  time.Thursday,
+	"Friday":    time.Friday,
+	"Saturday":  time.Saturday,
+}
+
 type SchedulePollCommand struct {
 	GuildID string
 	Day     string
@@ -470,15 +777,6 @@ func (c *SchedulePollCommand) Execute(ctx context.Context, cl *clients.Clients)
 		return nil, fmt.Errorf("getGuild: %v", err)
 	}
 
-	dayLookup := map[string]time.Weekday{
-		"Sunday":    time.Sunday,
-		"Monday":    time.Monday,
-		"Tuesday":   time.Tuesday,
-		"Wednesday": time.Wednesday,
-		"Thursday":  time.Thursday,
-		"Friday":    time.Friday,
-		"Saturday":  time.Saturday,
-	}
 	day := dayLookup[c.Day]
 
 	err = g.SetSchedule(ctx, &guild.ScheduleInfo{
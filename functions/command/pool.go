@@ -15,14 +15,19 @@ type PoolListCommand struct {
 	GuildID      string
 	Name         string
 	ActivityType string
-	CustomID     *customid.CustomID
+	Tag          string
+	// "newest" or empty to sort by name
+	Sort     string
+	CustomID *customid.CustomID
 }
 
-func NewPoolListCommand(guildID, name, activityType string) *PoolListCommand {
+func NewPoolListCommand(guildID, name, activityType, tag, sort string) *PoolListCommand {
 	return &PoolListCommand{
 		GuildID:      guildID,
 		Name:         name,
 		ActivityType: activityType,
+		Tag:          tag,
+		Sort:         sort,
 	}
 }
 
@@ -31,6 +36,8 @@ func NewPoolListCommandFromCustomID(guildID string, customID *customid.CustomID)
 		GuildID:      guildID,
 		Name:         customID.Filter().Name,
 		ActivityType: customID.Filter().Type,
+		Tag:          customID.Filter().Tag,
+		Sort:         customID.Filter().Sort,
 		CustomID:     customID,
 	}
 }
@@ -45,10 +52,16 @@ func (c *PoolListCommand) Execute(ctx context.Context, cl *clients.Clients) (*di
 	default:
 		actType = c.ActivityType
 	}
+	tag := ""
+	if tags := activity.NormalizeTags(c.Tag); len(tags) > 0 {
+		tag = tags[0]
+	}
 	if c.CustomID == nil {
 		customID, err := customid.CreateCustomID(ctx, "pool", customid.Filter{
 			Name: c.Name,
 			Type: actType,
+			Tag:  tag,
+			Sort: c.Sort,
 		}, 0, cl)
 		if err != nil {
 			return nil, fmt.Errorf("CreateCustomID: %v", err)
@@ -59,7 +72,9 @@ func (c *PoolListCommand) Execute(ctx context.Context, cl *clients.Clients) (*di
 	entries, lastPage, err := activity.GetActivitiesPage(ctx, c.GuildID, c.CustomID.Page, &activity.ActivitesPageOptions{
 		Name:            c.Name,
 		Type:            activity.ActivityType(actType),
+		Tag:             tag,
 		NominationsOnly: false,
+		Newest:          c.Sort == "newest",
 	}, cl)
 	if err != nil {
 		return nil, fmt.Errorf("GetActivitesPage: %v", err)
@@ -0,0 +1,233 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+type PollPreviewCommand struct {
+	GuildID string
+}
+
+func NewPollPreviewCommand(guildID string) *PollPreviewCommand {
+	return &PollPreviewCommand{
+		GuildID: guildID,
+	}
+}
+
+func (c *PollPreviewCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	entries, err := generatePollEntries(ctx, g, nil, cl)
+	if err != nil {
+		return nil, fmt.Errorf("generatePollEntries: %v", err)
+	}
+	preview := &guild.PollPreview{
+		Entries: entries,
+	}
+	err = g.SetPollPreview(ctx, preview)
+	if err != nil {
+		return nil, fmt.Errorf("setPollPreview: %v", err)
+	}
+	return buildPollPreview(preview), nil
+}
+
+type PollPreviewRerollCommand struct {
+	GuildID string
+}
+
+func NewPollPreviewRerollCommand(guildID string) *PollPreviewRerollCommand {
+	return &PollPreviewRerollCommand{
+		GuildID: guildID,
+	}
+}
+
+func (c *PollPreviewRerollCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	preview, err := g.GetPollPreview(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getPollPreview: %v", err)
+	}
+	if preview == nil {
+		return expiredPollPreview(), nil
+	}
+	// Keep everything except the unlocked random slots
+	seed := slices.DeleteFunc(slices.Clone(preview.Entries), func(entry guild.PollEntry) bool {
+		return !entry.Locked && slotProviders[entry.Source].random
+	})
+	entries, err := generatePollEntries(ctx, g, seed, cl)
+	if err != nil {
+		return nil, fmt.Errorf("generatePollEntries: %v", err)
+	}
+	preview.Entries = entries
+	err = g.SetPollPreview(ctx, preview)
+	if err != nil {
+		return nil, fmt.Errorf("setPollPreview: %v", err)
+	}
+	return buildPollPreview(preview), nil
+}
+
+type PollPreviewLockCommand struct {
+	GuildID string
+	Locked  []string
+}
+
+func NewPollPreviewLockCommand(guildID string, locked []string) *PollPreviewLockCommand {
+	return &PollPreviewLockCommand{
+		GuildID: guildID,
+		Locked:  locked,
+	}
+}
+
+func (c *PollPreviewLockCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	preview, err := g.GetPollPreview(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getPollPreview: %v", err)
+	}
+	if preview == nil {
+		return expiredPollPreview(), nil
+	}
+	for i := range preview.Entries {
+		preview.Entries[i].Locked = slices.Contains(c.Locked, strconv.Itoa(i))
+	}
+	err = g.SetPollPreview(ctx, preview)
+	if err != nil {
+		return nil, fmt.Errorf("setPollPreview: %v", err)
+	}
+	return buildPollPreview(preview), nil
+}
+
+type PollPreviewPublishCommand struct {
+	GuildID string
+}
+
+func NewPollPreviewPublishCommand(guildID string) *PollPreviewPublishCommand {
+	return &PollPreviewPublishCommand{
+		GuildID: guildID,
+	}
+}
+
+func (c *PollPreviewPublishCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	preview, err := g.GetPollPreview(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getPollPreview: %v", err)
+	}
+	if preview == nil {
+		return expiredPollPreview(), nil
+	}
+	activePoll, err := g.GetActivePoll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getActivePoll: %v", err)
+	}
+	if activePoll != nil {
+		return utils.NewWebhookEdit("There is already an active poll"), nil
+	}
+	pollCmd := NewCreatePollCommand(c.GuildID, preview.Entries, 48, false)
+	response, err := pollCmd.Execute(ctx, cl)
+	if err != nil {
+		return nil, fmt.Errorf("createPollCommand: %v", err)
+	}
+	err = g.ClearPollPreview(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("clearPollPreview: %v", err)
+	}
+	response.Embeds = &[]*discordgo.MessageEmbed{}
+	response.Components = &[]discordgo.MessageComponent{}
+	return response, nil
+}
+
+func expiredPollPreview() *discordgo.WebhookEdit {
+	response := utils.NewWebhookEdit("This preview is no longer available. Run `/poll preview` again")
+	response.Embeds = &[]*discordgo.MessageEmbed{}
+	response.Components = &[]discordgo.MessageComponent{}
+	return response
+}
+
+func buildPollPreview(preview *guild.PollPreview) *discordgo.WebhookEdit {
+	lines := make([]string, 0, len(preview.Entries))
+	lockOptions := make([]discordgo.SelectMenuOption, 0, len(preview.Entries))
+	for i, entry := range preview.Entries {
+		line := fmt.Sprintf("%v %v", entry.Emoji, entry.Name)
+		if entry.Locked {
+			line += " 🔒"
+		}
+		lines = append(lines, line)
+		lockOptions = append(lockOptions, discordgo.SelectMenuOption{
+			Label:   truncateActivityName(entry.Name),
+			Value:   strconv.Itoa(i),
+			Emoji:   &discordgo.ComponentEmoji{Name: entry.Emoji},
+			Default: entry.Locked,
+		})
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "The pool is empty")
+	}
+	content := ""
+	embeds := []*discordgo.MessageEmbed{
+		{
+			Title:       "Poll preview",
+			Description: strings.Join(lines, "\n"),
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: fmt.Sprintf("%v pinned %v nomination %v random 🔒 locked", PINNED_EMOJI, NOMINATION_EMOJI, RANDOM_EMOJI),
+			},
+		},
+	}
+	components := make([]discordgo.MessageComponent, 0, 2)
+	if len(lockOptions) > 0 {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					Placeholder: "Lock entries",
+					Options:     lockOptions,
+					MinValues:   firestore.Ptr(0),
+					MaxValues:   len(lockOptions),
+					CustomID:    `{"type":"poll-preview-lock"}`,
+				},
+			},
+		})
+	}
+	components = append(components, discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Re-roll random",
+				Style:    discordgo.SecondaryButton,
+				Emoji:    &discordgo.ComponentEmoji{Name: "🎲"},
+				CustomID: `{"type":"poll-preview-reroll"}`,
+			},
+			discordgo.Button{
+				Label:    "Publish",
+				Style:    discordgo.PrimaryButton,
+				Disabled: len(preview.Entries) == 0,
+				CustomID: `{"type":"poll-preview-publish"}`,
+			},
+		},
+	})
+	return &discordgo.WebhookEdit{
+		Content:    &content,
+		Embeds:     &embeds,
+		Components: &components,
+	}
+}
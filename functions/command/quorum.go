@@ -0,0 +1,234 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// Length in hours of a poll that extends one that missed quorum
+const EXTENSION_DURATION int = 24
+
+type QuorumCommand struct {
+	GuildID     string
+	MinVotes    int
+	RoleID      string
+	RolePercent int
+	Action      string
+}
+
+func NewQuorumCommand(guildID string, minVotes int, roleID string, rolePercent int, action string) *QuorumCommand {
+	return &QuorumCommand{
+		GuildID:     guildID,
+		MinVotes:    minVotes,
+		RoleID:      roleID,
+		RolePercent: rolePercent,
+		Action:      action,
+	}
+}
+
+func (c *QuorumCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	if c.MinVotes == 0 && c.RoleID == "" {
+		err = g.ClearQuorum(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("clearQuorum: %v", err)
+		}
+		return utils.NewWebhookEdit("Removed the poll quorum"), nil
+	}
+	if c.RoleID != "" && c.RolePercent == 0 {
+		return utils.NewWebhookEdit("A percentage is required when setting a role"), nil
+	}
+	action := guild.QuorumAction(c.Action)
+	if action == "" {
+		action = guild.QUORUM_KEEP_FOW
+	}
+	quorum := &guild.QuorumInfo{
+		MinVotes:    c.MinVotes,
+		RoleID:      c.RoleID,
+		RolePercent: c.RolePercent,
+		Action:      action,
+	}
+	err = g.SetQuorum(ctx, quorum)
+	if err != nil {
+		return nil, fmt.Errorf("setQuorum: %v", err)
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Set the poll quorum to %v", describeQuorum(quorum))), nil
+}
+
+func describeQuorum(quorum *guild.QuorumInfo) string {
+	description := ""
+	if quorum.MinVotes > 0 {
+		description = fmt.Sprintf("%v votes", quorum.MinVotes)
+	}
+	if quorum.RoleID != "" {
+		if description != "" {
+			description += " and "
+		}
+		description += fmt.Sprintf("%v%% of <@&%v>", quorum.RolePercent, quorum.RoleID)
+	}
+	return fmt.Sprintf("%v. If it isn't met: %v", description, describeQuorumAction(quorum.Action))
+}
+
+func describeQuorumAction(action guild.QuorumAction) string {
+	switch action {
+	case guild.QUORUM_EXTEND:
+		return fmt.Sprintf("the poll is extended by %v hours", EXTENSION_DURATION)
+	case guild.QUORUM_NEW_POLL:
+		return "a new poll is started"
+	default:
+		return "the current flavor of the week is kept"
+	}
+}
+
+// requiredVotes returns the number of voters needed for a poll to count
+func requiredVotes(ctx context.Context, s *discordgo.Session, guildID string, quorum *guild.QuorumInfo) (int, error) {
+	required := quorum.MinVotes
+	if quorum.RoleID != "" && quorum.RolePercent > 0 {
+		members, err := getRoleMembers(s, guildID, quorum.RoleID)
+		if err != nil {
+			return 0, fmt.Errorf("getRoleMembers: %v", err)
+		}
+		// ceil(members * percent / 100)
+		roleRequired := (len(members)*quorum.RolePercent + 99) / 100
+		ctxzap.Info(ctx, fmt.Sprintf("%v members have role %v. %v votes required", len(members), quorum.RoleID, roleRequired))
+		required = max(required, roleRequired)
+	}
+	return required, nil
+}
+
+// getRoleMembers returns the IDs of the guild members with the role or every member when roleID is empty
+func getRoleMembers(s *discordgo.Session, guildID, roleID string) ([]string, error) {
+	results := make([]string, 0)
+	after := ""
+	for {
+		members, err := s.GuildMembers(guildID, after, 1000)
+		if err != nil {
+			return nil, fmt.Errorf("guildMembers: %v", err)
+		}
+		for _, member := range members {
+			if member.User != nil && !member.User.Bot && (roleID == "" || slices.Contains(member.Roles, roleID)) {
+				results = append(results, member.User.ID)
+			}
+		}
+		if len(members) < 1000 {
+			return results, nil
+		}
+		after = members[len(members)-1].User.ID
+	}
+}
+
+// checkQuorum returns whether enough users voted in the poll along with the required votes
+func checkQuorum(ctx context.Context, s *discordgo.Session, g *guild.Guild, votes int) (bool, int, error) {
+	quorum, err := g.GetQuorum(ctx)
+	if err != nil {
+		return false, 0, fmt.Errorf("getQuorum: %v", err)
+	}
+	// Polls of guilds without a quorum always count
+	if quorum == nil {
+		return true, 0, nil
+	}
+	required, err := requiredVotes(ctx, s, g.GetGuildId(), quorum)
+	if err != nil {
+		return false, 0, fmt.Errorf("requiredVotes: %v", err)
+	}
+	ctxzap.Info(ctx, fmt.Sprintf("Poll had %v voters. %v required", votes, required))
+	return votes >= required, required, nil
+}
+
+// handleMissedQuorum runs the guild's configured action for a poll that didn't get enough votes
+func handleMissedQuorum(ctx context.Context, s *discordgo.Session, g *guild.Guild, pollInfo *guild.PollInfo, result *pollResult, required int, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	quorum, err := g.GetQuorum(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getQuorum: %v", err)
+	}
+	// The quorum was removed while the poll ended
+	if quorum == nil {
+		quorum = &guild.QuorumInfo{Action: guild.QUORUM_KEEP_FOW}
+	}
+	action := quorum.Action
+	// Only extend a poll once
+	if action == guild.QUORUM_EXTEND && pollInfo.Extended {
+		action = guild.QUORUM_KEEP_FOW
+	}
+
+	_, err = s.ChannelMessageSendComplex(pollInfo.ChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       "🗳️ Quorum not met",
+				Description: fmt.Sprintf("The poll got %v of the %v votes needed.\nSo %v.", result.voterCount, required, describeQuorumAction(action)),
+				Color:       2326507,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("channelMessageSendComplex: %v", err)
+	}
+
+	if action == guild.QUORUM_EXTEND && pollInfo.IsComponentPoll() {
+		return extendComponentPoll(ctx, s, g, pollInfo)
+	}
+	if pollInfo.IsComponentPoll() {
+		err = closeComponentPoll(s, pollInfo)
+		if err != nil {
+			return nil, fmt.Errorf("closeComponentPoll: %v", err)
+		}
+	}
+	switch action {
+	case guild.QUORUM_EXTEND:
+		// Native polls can't be reopened so a new one continues the vote
+		pollCmd := NewCreatePollCommand(g.GetGuildId(), result.entries, EXTENSION_DURATION, pollInfo.SuddenDeath)
+		pollCmd.SkipActivePollCheck(true)
+		pollCmd.SetExtension(result.voters)
+		return pollCmd.Execute(ctx, cl)
+	case guild.QUORUM_NEW_POLL:
+		pollCmd := NewStartPollCommand(g.GetGuildId())
+		pollCmd.SkipActivePollCheck(true)
+		return pollCmd.Execute(ctx, cl)
+	default:
+		// Nominations are kept for the next poll
+		err = g.ClearActivePoll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("clearActivePoll: %v", err)
+		}
+		return utils.NewWebhookEdit("Poll ended without enough votes. The flavor of the week is unchanged"), nil
+	}
+}
+
+// extendComponentPoll keeps a ranked or hidden poll open longer with the ballots already cast
+func extendComponentPoll(ctx context.Context, s *discordgo.Session, g *guild.Guild, pollInfo *guild.PollInfo) (*discordgo.WebhookEdit, error) {
+	closesAt := time.Now().UTC().Add(time.Duration(EXTENSION_DURATION) * time.Hour)
+	err := g.ExtendActivePoll(ctx, closesAt)
+	if err != nil {
+		return nil, fmt.Errorf("extendActivePoll: %v", err)
+	}
+	pollInfo.ClosesAt = &closesAt
+	pollInfo.Extended = true
+	var embeds []*discordgo.MessageEmbed
+	var components []discordgo.MessageComponent
+	if pollInfo.Ranked {
+		embeds, components = buildRankedPoll(pollInfo)
+	} else {
+		embeds, components = buildHiddenPoll(pollInfo, nil)
+	}
+	_, err = s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    pollInfo.ChannelID,
+		ID:         pollInfo.MessageID,
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("channelMessageEditComplex: %v", err)
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Poll extended by %v hours", EXTENSION_DURATION)), nil
+}
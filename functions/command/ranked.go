@@ -0,0 +1,298 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// Number of entries each user ranks
+const MAX_RANKS int = 3
+
+var rankNames = []string{"1st choice", "2nd choice", "3rd choice"}
+
+type VotingModeCommand struct {
+	GuildID string
+	Mode    string
+}
+
+func NewVotingModeCommand(guildID, mode string) *VotingModeCommand {
+	return &VotingModeCommand{
+		GuildID: guildID,
+		Mode:    mode,
+	}
+}
+
+func (c *VotingModeCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	if c.Mode != guild.VOTING_NATIVE && c.Mode != guild.VOTING_RANKED && c.Mode != guild.VOTING_HIDDEN {
+		return utils.NewWebhookEdit(fmt.Sprintf("Unknown voting mode: %v", c.Mode)), nil
+	}
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	err = g.SetVotingMode(ctx, c.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("setVotingMode: %v", err)
+	}
+	switch c.Mode {
+	case guild.VOTING_RANKED:
+		return utils.NewWebhookEdit(fmt.Sprintf("Polls will use ranked-choice voting. Members rank their top %v and the winner is found by instant-runoff", MAX_RANKS)), nil
+	case guild.VOTING_HIDDEN:
+		return utils.NewWebhookEdit("Polls will use hidden voting. Results are revealed when the poll ends"), nil
+	}
+	return utils.NewWebhookEdit("Polls will use Discord's native polls"), nil
+}
+
+// pollCandidates returns the names that can be voted for in a component poll
+func pollCandidates(pollInfo *guild.PollInfo) []string {
+	candidates := make([]string, 0, len(pollInfo.Entries)+1)
+	for _, entry := range pollInfo.Entries {
+		candidates = append(candidates, entry.Name)
+	}
+	if !pollInfo.SuddenDeath && !pollInfo.Bracket {
+		candidates = append(candidates, REROLL_ANSWER)
+	}
+	return candidates
+}
+
+func componentPollTitle(pollInfo *guild.PollInfo) string {
+	if pollInfo.Bracket {
+		return "🏆 Tournament match"
+	}
+	return "What should the flavor of the week be?"
+}
+
+func buildRankedPoll(pollInfo *guild.PollInfo) ([]*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	closed := pollInfo.ClosesAt == nil || time.Now().After(*pollInfo.ClosesAt)
+	lines := make([]string, 0, len(pollInfo.Entries)+1)
+	options := make([]discordgo.SelectMenuOption, 0, len(pollInfo.Entries)+1)
+	for i, name := range pollCandidates(pollInfo) {
+		emoji := "🎲"
+		if i < len(pollInfo.Entries) {
+			emoji = pollInfo.Entries[i].Emoji
+		}
+		lines = append(lines, fmt.Sprintf("%v %v", emoji, name))
+		options = append(options, discordgo.SelectMenuOption{
+			Label: truncateActivityName(name),
+			Value: strconv.Itoa(i),
+			Emoji: &discordgo.ComponentEmoji{Name: emoji},
+		})
+	}
+	description := strings.Join(lines, "\n")
+	if !closed {
+		description += fmt.Sprintf("\n\nRank your top %v. Voting closes <t:%v:R>", MAX_RANKS, pollInfo.ClosesAt.Unix())
+	}
+	footer := fmt.Sprintf("%v ballots cast", len(castBallots(pollInfo)))
+	if closed {
+		footer += " • Voting has closed"
+	}
+	embeds := []*discordgo.MessageEmbed{
+		{
+			Title:       componentPollTitle(pollInfo),
+			Description: description,
+			Color:       2326507,
+			Footer: &discordgo.MessageEmbedFooter{
+				Text: footer,
+			},
+		},
+	}
+	components := make([]discordgo.MessageComponent, 0, MAX_RANKS)
+	if closed {
+		return embeds, components
+	}
+	for rank := 1; rank <= min(MAX_RANKS, len(options)); rank++ {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					Placeholder: rankNames[rank-1],
+					Options:     options,
+					CustomID:    fmt.Sprintf(`{"type":"ranked-vote-%v"}`, rank),
+				},
+			},
+		})
+	}
+	return embeds, components
+}
+
+type RankedVoteCommand struct {
+	GuildID     string
+	UserID      string
+	Rank        int
+	Values      []string
+	interaction *discordgo.Interaction
+}
+
+func NewRankedVoteCommand(guildID, userID string, rank int, values []string, interaction *discordgo.Interaction) *RankedVoteCommand {
+	return &RankedVoteCommand{
+		GuildID:     guildID,
+		UserID:      userID,
+		Rank:        rank,
+		Values:      values,
+		interaction: interaction,
+	}
+}
+
+func (c *RankedVoteCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	s, err := cl.Discord()
+	if err != nil {
+		return nil, fmt.Errorf("discord: %v", err)
+	}
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	pollInfo, err := g.GetActivePoll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getActivePoll: %v", err)
+	}
+	if !isVotingOpen(pollInfo, c.interaction) || !pollInfo.Ranked {
+		err = replyEphemeral(s, c.interaction, "Voting on this poll has closed")
+		if err != nil {
+			return nil, fmt.Errorf("reply: %v", err)
+		}
+		components := []discordgo.MessageComponent{}
+		return &discordgo.WebhookEdit{
+			Components: &components,
+		}, nil
+	}
+
+	candidates := pollCandidates(pollInfo)
+	if len(c.Values) == 0 || c.Rank < 1 || c.Rank > MAX_RANKS {
+		return nil, fmt.Errorf("invalid vote: rank %v values %v", c.Rank, c.Values)
+	}
+	i, err := strconv.Atoi(c.Values[0])
+	if err != nil || i < 0 || i >= len(candidates) {
+		return nil, fmt.Errorf("invalid vote value: %v", c.Values[0])
+	}
+	choice := candidates[i]
+
+	ballot := make([]string, MAX_RANKS)
+	copy(ballot, pollInfo.Ballots[c.UserID])
+	// An entry can only be ranked once
+	for j := range ballot {
+		if ballot[j] == choice {
+			ballot[j] = ""
+		}
+	}
+	ballot[c.Rank-1] = choice
+	err = g.SetBallot(ctx, c.UserID, ballot)
+	if err != nil {
+		return nil, fmt.Errorf("setBallot: %v", err)
+	}
+
+	lines := make([]string, 0, MAX_RANKS)
+	for j, name := range ballot {
+		if name == "" {
+			name = "-"
+		}
+		lines = append(lines, fmt.Sprintf("%v. %v", j+1, name))
+	}
+	err = replyEphemeral(s, c.interaction, fmt.Sprintf("Your ballot:\n%v", strings.Join(lines, "\n")))
+	if err != nil {
+		return nil, fmt.Errorf("reply: %v", err)
+	}
+
+	embeds, components := buildRankedPoll(pollInfo)
+	return &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	}, nil
+}
+
+// isVotingOpen returns whether the interaction is for the active poll and it hasn't closed
+func isVotingOpen(pollInfo *guild.PollInfo, interaction *discordgo.Interaction) bool {
+	return pollInfo != nil && interaction.Message != nil && pollInfo.MessageID == interaction.Message.ID &&
+		pollInfo.ClosesAt != nil && time.Now().Before(*pollInfo.ClosesAt)
+}
+
+// replyEphemeral sends a message only the voter can see since the interaction response updates the poll
+func replyEphemeral(s *discordgo.Session, interaction *discordgo.Interaction, content string) error {
+	_, err := s.FollowupMessageCreate(interaction, false, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	return err
+}
+
+// castBallots returns the non-empty ballots of the poll with unranked slots removed
+func castBallots(pollInfo *guild.PollInfo) map[string][]string {
+	ballots := make(map[string][]string)
+	for user, ballot := range pollInfo.Ballots {
+		ranked := slices.DeleteFunc(slices.Clone(ballot), func(name string) bool {
+			return name == ""
+		})
+		if len(ranked) > 0 {
+			ballots[user] = ranked
+		}
+	}
+	return ballots
+}
+
+// instantRunoff repeatedly eliminates the candidates with the fewest first
+// preferences until one has a majority. Returns every remaining candidate
+// with true when they can't be separated.
+func instantRunoff(ballots [][]string, candidates []string) ([]string, bool) {
+	remaining := slices.Clone(candidates)
+	for len(remaining) > 1 {
+		counts := make(map[string]int)
+		active := 0
+		for _, ballot := range ballots {
+			i := slices.IndexFunc(ballot, func(name string) bool {
+				return slices.Contains(remaining, name)
+			})
+			if i == -1 {
+				// All of the ballot's choices have been eliminated
+				continue
+			}
+			counts[ballot[i]]++
+			active++
+		}
+		for _, name := range remaining {
+			if counts[name]*2 > active {
+				return []string{name}, false
+			}
+		}
+		fewest := counts[remaining[0]]
+		for _, name := range remaining {
+			fewest = min(fewest, counts[name])
+		}
+		next := slices.DeleteFunc(slices.Clone(remaining), func(name string) bool {
+			return counts[name] == fewest
+		})
+		if len(next) == 0 {
+			// Everyone left is tied
+			break
+		}
+		remaining = next
+	}
+	return remaining, len(remaining) > 1
+}
+
+// rankedPollResult computes the instant-runoff result of a ranked poll
+func rankedPollResult(ctx context.Context, pollInfo *guild.PollInfo) *pollResult {
+	voters := castBallots(pollInfo)
+	ballots := make([][]string, 0, len(voters))
+	for _, ballot := range voters {
+		ballots = append(ballots, ballot)
+	}
+	winners, tie := instantRunoff(ballots, pollCandidates(pollInfo))
+	ctxzap.Info(ctx, fmt.Sprintf("Instant-runoff of %v ballots: %v", len(ballots), winners))
+	return &pollResult{
+		entries:    pollInfo.Entries,
+		voters:     voters,
+		voterCount: len(voters),
+		winners:    winners,
+		tie:        tie,
+	}
+}
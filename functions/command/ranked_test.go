@@ -0,0 +1,68 @@
+package command
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInstantRunoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		ballots    [][]string
+		candidates []string
+		want       []string
+		wantTie    bool
+	}{
+		{
+			name:       "first round majority",
+			ballots:    [][]string{{"A"}, {"A"}, {"B"}},
+			candidates: []string{"A", "B"},
+			want:       []string{"A"},
+		},
+		{
+			name:       "unranked candidates count as nothing",
+			ballots:    [][]string{{"A"}, {"B"}, {"B"}},
+			candidates: []string{"A", "B", "C"},
+			want:       []string{"B"},
+		},
+		{
+			name:       "last place transfers",
+			ballots:    [][]string{{"A", "B"}, {"A", "C"}, {"B", "A"}, {"B", "C"}, {"C", "B"}},
+			candidates: []string{"A", "B", "C"},
+			want:       []string{"B"},
+		},
+		{
+			name:       "tied last places are eliminated together",
+			ballots:    [][]string{{"A"}, {"A"}, {"A"}, {"B"}, {"B"}, {"C", "B"}, {"D", "B"}},
+			candidates: []string{"A", "B", "C", "D"},
+			want:       []string{"B"},
+		},
+		{
+			name:       "everyone tied",
+			ballots:    [][]string{{"A"}, {"B"}},
+			candidates: []string{"A", "B"},
+			want:       []string{"A", "B"},
+			wantTie:    true,
+		},
+		{
+			name:       "exhausted ballots leave a tie",
+			ballots:    [][]string{{"A"}, {"A"}, {"B"}, {"B"}, {"C"}},
+			candidates: []string{"A", "B", "C"},
+			want:       []string{"A", "B"},
+			wantTie:    true,
+		},
+		{
+			name:       "no ballots",
+			ballots:    [][]string{},
+			candidates: []string{"A", "B"},
+			want:       []string{"A", "B"},
+			wantTie:    true,
+		},
+	}
+	for _, test := range tests {
+		got, tie := instantRunoff(test.ballots, test.candidates)
+		if !slices.Equal(got, test.want) || tie != test.wantTie {
+			t.Errorf(`%v: instantRunoff = %v, %v, want %v, %v`, test.name, got, tie, test.want, test.wantTie)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/history"
+	"github.com/bwmarrin/discordgo"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+const MAX_RATING int = 5
+
+const MAX_RATING_COMMENT_LENGTH int = 300
+
+// postRatingPrompt asks members to rate the flavor of the week that is ending.
+// Must run before the next flavor of the week is recorded
+func postRatingPrompt(ctx context.Context, g *guild.Guild, cl *clients.Clients) error {
+	chanID, err := g.GetPollChannel(ctx)
+	if err != nil {
+		return fmt.Errorf("getPollChannel: %v", err)
+	}
+	if chanID == nil {
+		return nil
+	}
+	fows, err := history.GetRecentFows(ctx, g.GetGuildId(), 1, cl)
+	if err != nil {
+		return fmt.Errorf("getRecentFows: %v", err)
+	}
+	// Ratings are tied to the week being closed. Only prompt once for it
+	if len(fows) == 0 || fows[0].RatingMessageID != "" {
+		return nil
+	}
+	record := fows[0]
+	s, err := cl.Discord()
+	if err != nil {
+		return fmt.Errorf("discord: %v", err)
+	}
+	buttons := make([]discordgo.MessageComponent, 0, MAX_RATING)
+	for score := 1; score <= MAX_RATING; score++ {
+		buttons = append(buttons, discordgo.Button{
+			Label:    fmt.Sprint(score),
+			Emoji:    &discordgo.ComponentEmoji{Name: "⭐"},
+			Style:    discordgo.SecondaryButton,
+			CustomID: fmt.Sprintf(`{"type":"rate-%v"}`, score),
+		})
+	}
+	msg, err := s.ChannelMessageSendComplex(*chanID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{buildRatingEmbed(record.Activity, nil)},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: buttons,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("channelMessageSendComplex: %v", err)
+	}
+	return history.SetRatingMessage(ctx, record, msg.ID, cl)
+}
+
+func buildRatingEmbed(name string, ratings map[string]activity.Rating) *discordgo.MessageEmbed {
+	description := "Rate it from 1 to 5. You can leave a comment too"
+	if rating := activity.FormatRating(ratings); rating != "" {
+		description += fmt.Sprintf("\n\n%v", rating)
+	}
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("How was %v?", name),
+		Description: description,
+		Color:       2326507,
+	}
+}
+
+// ratingModal returns the modal asking for a comment when a rating button is pressed.
+// Returns nil for other components.
+func ratingModal(data discordgo.MessageComponentInteractionData) *discordgo.InteractionResponse {
+	var customID struct {
+		Type string `json:"type"`
+	}
+	err := json.Unmarshal([]byte(data.CustomID), &customID)
+	if err != nil || !strings.HasPrefix(customID.Type, "rate-") {
+		return nil
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: data.CustomID,
+			Title:    fmt.Sprintf("Rating: %v/%v", strings.TrimPrefix(customID.Type, "rate-"), MAX_RATING),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:  "comment",
+							Label:     "Comment",
+							Style:     discordgo.TextInputParagraph,
+							Required:  false,
+							MaxLength: MAX_RATING_COMMENT_LENGTH,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type RateCommand struct {
+	GuildID     string
+	UserID      string
+	Score       int
+	Comment     string
+	interaction *discordgo.Interaction
+}
+
+func NewRateCommand(guildID, userID string, score int, comment string, interaction *discordgo.Interaction) *RateCommand {
+	return &RateCommand{
+		GuildID:     guildID,
+		UserID:      userID,
+		Score:       score,
+		Comment:     comment,
+		interaction: interaction,
+	}
+}
+
+func (c *RateCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	if c.Score < 1 || c.Score > MAX_RATING || c.interaction.Message == nil {
+		return nil, fmt.Errorf("invalid rating: %v", c.Score)
+	}
+	s, err := cl.Discord()
+	if err != nil {
+		return nil, fmt.Errorf("discord: %v", err)
+	}
+	closed := &discordgo.WebhookEdit{
+		Components: &[]discordgo.MessageComponent{},
+	}
+	record, err := history.GetFowByRatingMessage(ctx, c.GuildID, c.interaction.Message.ID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getFowByRatingMessage: %v", err)
+	}
+	if record == nil {
+		return closed, nil
+	}
+	act, err := activity.GetActivity(ctx, record.Activity, c.GuildID, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			err = replyEphemeral(s, c.interaction, fmt.Sprintf("%v was removed from the pool", record.Activity))
+			if err != nil {
+				ctxzap.Error(ctx, fmt.Sprintf("replyEphemeral: %v", err))
+			}
+			return closed, nil
+		}
+		return nil, fmt.Errorf("getActivity: %v", err)
+	}
+	err = act.SetRating(ctx, c.UserID, activity.Rating{
+		Score:   c.Score,
+		Comment: strings.TrimSpace(c.Comment),
+		RatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("act.SetRating: %v", err)
+	}
+	err = replyEphemeral(s, c.interaction, fmt.Sprintf("You rated %v %v/%v", act.Name(), c.Score, MAX_RATING))
+	if err != nil {
+		ctxzap.Error(ctx, fmt.Sprintf("replyEphemeral: %v", err))
+	}
+	return &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{buildRatingEmbed(act.Name(), act.Ratings())},
+	}, nil
+}
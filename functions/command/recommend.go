@@ -0,0 +1,211 @@
+package command
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/customid"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/dimuska139/rawg-sdk-go/v3"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// Number of favorite games recommendations are based on
+const RECOMMEND_SEEDS int = 3
+
+// Number of the favorites' most common genres and tags searched for
+const RECOMMEND_GENRES int = 2
+const RECOMMEND_TAGS int = 3
+
+// Number of games fetched by genre and tag
+const RECOMMEND_SEARCH_SIZE int = 40
+
+type RecommendCommand struct {
+	GuildID  string
+	Page     int
+	CustomID *customid.CustomID
+}
+
+func NewRecommendCommand(guildID string) *RecommendCommand {
+	return &RecommendCommand{
+		GuildID: guildID,
+	}
+}
+
+func NewRecommendCommandFromCustomID(guildID string, customID *customid.CustomID) *RecommendCommand {
+	return &RecommendCommand{
+		GuildID:  guildID,
+		Page:     customID.Page,
+		CustomID: customID,
+	}
+}
+
+func (c *RecommendCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	var results *customid.Results
+	if c.CustomID != nil {
+		results = c.CustomID.Results()
+	}
+	// Recommendations are computed once and kept with the custom ID for the other pages
+	if results == nil {
+		games, seeds, err := recommendGames(ctx, c.GuildID, cl)
+		if err != nil {
+			return nil, fmt.Errorf("recommendGames: %v", err)
+		}
+		if len(seeds) == 0 {
+			return utils.NewWebhookEdit("Recommendations are based on past flavors of the week and their ratings. Finish a poll with a game first"), nil
+		}
+		if len(games) == 0 {
+			return utils.NewWebhookEdit("No recommendations found"), nil
+		}
+		results = &customid.Results{
+			Heading: fmt.Sprintf("Because you liked %v", strings.Join(seeds, ", ")),
+			Entries: make([]customid.Result, 0, len(games)),
+		}
+		for _, game := range games {
+			results.Entries = append(results.Entries, customid.Result{
+				Name:     game.Name,
+				Value:    game.Slug,
+				ImageURL: game.ImageBackground,
+			})
+		}
+		customID, err := customid.CreateResultsCustomID(ctx, "recommend", results, c.Page, cl)
+		if err != nil {
+			return nil, fmt.Errorf("CreateResultsCustomID: %v", err)
+		}
+		c.CustomID = customID
+	}
+
+	start := min(c.Page*SEARCH_PAGE_SIZE, len(results.Entries))
+	page := results.Entries[start:min(start+SEARCH_PAGE_SIZE, len(results.Entries))]
+	entries := make([]utils.GameEntry, 0, len(page))
+	menuOptions := make([]discordgo.SelectMenuOption, 0, len(page))
+	for _, res := range page {
+		entries = append(entries, utils.GameEntry{
+			Name:     res.Name,
+			ImageURL: res.ImageURL,
+		})
+		menuOptions = append(menuOptions, discordgo.SelectMenuOption{
+			Label: res.Name,
+			Value: res.Value,
+		})
+	}
+	menuCustomID := fmt.Sprintf(`{"type":"add","page":%v}`, c.Page)
+
+	// ceil(len(results) / SEARCH_PAGE_SIZE)
+	totalPages := (len(results.Entries) + SEARCH_PAGE_SIZE - 1) / SEARCH_PAGE_SIZE
+	edit := utils.BuildDiscordPage(entries, c.CustomID, &utils.PageOptions{
+		TotalPages: &totalPages,
+	}, &discordgo.SelectMenu{
+		MenuType:    discordgo.StringSelectMenu,
+		Placeholder: "Select a game to add to the pool",
+		Options:     menuOptions,
+		MaxValues:   1,
+		CustomID:    menuCustomID,
+	})
+	content := results.Heading
+	edit.Content = &content
+	return edit, nil
+}
+
+// recommendGames returns games not in the pool that are similar to the guild's favorite games.
+// The favorites used are also returned.
+func recommendGames(ctx context.Context, guildID string, cl *clients.Clients) ([]*rawg.Game, []string, error) {
+	pool, err := activity.GetAllActivities(ctx, guildID, cl)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getAllActivities: %v", err)
+	}
+	inPool := make(map[string]bool)
+	favorites := make([]activity.InnerActivity, 0)
+	for _, inAct := range pool {
+		inPool[strings.ToLower(inAct.Name)] = true
+		if inAct.GameInfo == nil {
+			continue
+		}
+		inPool[inAct.GameInfo.Slug] = true
+		if inAct.FowCount > 0 || len(inAct.Ratings) > 0 {
+			favorites = append(favorites, inAct)
+		}
+	}
+	// Each win counts as much as a star of the average rating
+	score := func(inAct activity.InnerActivity) float64 {
+		return float64(inAct.FowCount) + activity.AverageRating(inAct.Ratings)
+	}
+	slices.SortFunc(favorites, func(a, b activity.InnerActivity) int {
+		return cmp.Or(cmp.Compare(score(b), score(a)), cmp.Compare(a.Name, b.Name))
+	})
+	favorites = favorites[:min(RECOMMEND_SEEDS, len(favorites))]
+
+	weights := make(map[string]int)
+	games := make(map[string]*rawg.Game)
+	addGame := func(game *rawg.Game, weight int) {
+		if inPool[game.Slug] || inPool[strings.ToLower(game.Name)] {
+			return
+		}
+		weights[game.Slug] += weight
+		games[game.Slug] = game
+	}
+	genreCounts := make(map[string]int)
+	tagCounts := make(map[string]int)
+	seeds := make([]string, 0, len(favorites))
+	for _, favorite := range favorites {
+		seeds = append(seeds, favorite.Name)
+		detail, err := cl.Rawg().GetGame(ctx, favorite.GameInfo.Slug)
+		if err != nil {
+			ctxzap.Warn(ctx, fmt.Sprintf("GetGame: %v", err))
+			continue
+		}
+		for _, genre := range detail.Genres {
+			genreCounts[genre.Slug]++
+		}
+		for _, tag := range detail.Tags {
+			tagCounts[tag.Slug]++
+		}
+		suggested, err := cl.Rawg().GetSuggestedGames(ctx, detail.ID)
+		if err != nil {
+			ctxzap.Warn(ctx, fmt.Sprintf("GetSuggestedGames: %v", err))
+			continue
+		}
+		// Similar games are a stronger signal than a shared genre
+		for _, game := range suggested {
+			addGame(game, 2)
+		}
+	}
+	genres := mostCommon(genreCounts, RECOMMEND_GENRES)
+	tags := mostCommon(tagCounts, RECOMMEND_TAGS)
+	if len(genres) > 0 || len(tags) > 0 {
+		like, err := cl.Rawg().GetGamesLike(ctx, genres, tags, RECOMMEND_SEARCH_SIZE)
+		if err != nil {
+			return nil, nil, fmt.Errorf("GetGamesLike: %v", err)
+		}
+		for _, game := range like {
+			addGame(game, 1)
+		}
+	}
+
+	results := make([]*rawg.Game, 0, len(games))
+	for _, game := range games {
+		results = append(results, game)
+	}
+	slices.SortFunc(results, func(a, b *rawg.Game) int {
+		return cmp.Or(cmp.Compare(weights[b.Slug], weights[a.Slug]), cmp.Compare(b.Rating, a.Rating), cmp.Compare(a.Name, b.Name))
+	})
+	return results, seeds, nil
+}
+
+// mostCommon returns up to n keys with the highest counts
+func mostCommon(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+	return keys[:min(n, len(keys))]
+}
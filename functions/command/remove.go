@@ -12,13 +12,15 @@ import (
 
 type RemoveCommand struct {
 	GuildID string
+	UserID  string
 	Name    string
 	Force   bool
 }
 
-func NewRemoveCommand(guildID, name string, force bool) *RemoveCommand {
+func NewRemoveCommand(guildID, userID, name string, force bool) *RemoveCommand {
 	return &RemoveCommand{
 		GuildID: guildID,
+		UserID:  userID,
 		Name:    name,
 		Force:   force,
 	}
@@ -33,11 +35,13 @@ func (c *RemoveCommand) Execute(ctx context.Context, cl *clients.Clients) (*disc
 		}
 		return nil, err
 	}
-	err = act.RemoveActivity(ctx, c.Force)
+	// Whoever added the activity can remove it despite its nominations
+	createdBy, _ := act.Added()
+	err = act.RemoveActivity(ctx, c.Force || (createdBy != "" && createdBy == c.UserID))
 	if err != nil {
 		ae, ok := err.(*activity.ActivityError)
 		if ok && ae.Reason == activity.STILL_HAS_NOMINATIONS {
-			return utils.NewWebhookEdit(fmt.Sprintf("Cannot remove %v as it has nominations", c.Name)), nil
+			return utils.NewWebhookEdit(fmt.Sprintf("Cannot remove %v as it has nominations. Only who added it or an admin can", c.Name)), nil
 		}
 		return nil, fmt.Errorf("act.RemoveActivity: %v", err)
 	}
@@ -0,0 +1,127 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"cloud.google.com/go/firestore"
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/history"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/PinkNoize/flavor-of-the-week/functions/votes"
+	"github.com/bwmarrin/discordgo"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type RenameCommand struct {
+	GuildID string
+	OldName string
+	NewName string
+}
+
+func NewRenameCommand(guildID, oldName, newName string) *RenameCommand {
+	return &RenameCommand{
+		GuildID: guildID,
+		OldName: oldName,
+		NewName: newName,
+	}
+}
+
+func (c *RenameCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	newName := activity.NormalizeName(c.NewName)
+	if valid, reason := validateName(newName); !valid || newName == "" {
+		if newName == "" {
+			reason = "Empty name"
+		}
+		return utils.NewWebhookEdit(fmt.Sprintf("Invalid name: %v", reason)), nil
+	}
+	if newName == c.OldName {
+		return utils.NewWebhookEdit(fmt.Sprintf("%v already has that name", c.OldName)), nil
+	}
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	running, err := inRunningPoll(ctx, g, c.OldName)
+	if err != nil {
+		return nil, fmt.Errorf("inRunningPoll: %v", err)
+	}
+	if running {
+		return utils.NewWebhookEdit(fmt.Sprintf("%v is in the running poll. Rename it after the poll ends", c.OldName)), nil
+	}
+
+	fow, err := g.GetFow(ctx)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, fmt.Errorf("getFow: %v", err)
+	}
+	wasFow := fow != nil && *fow == c.OldName
+
+	// The guild is updated in the same transaction so its flavor of the week can't point at the old document
+	err = activity.Rename(ctx, c.GuildID, c.OldName, newName, func(tx *firestore.Transaction) error {
+		return g.RenameActivity(tx, c.OldName, newName)
+	}, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			return utils.NewWebhookEdit(fmt.Sprintf("%v does not exist", c.OldName)), nil
+		}
+		if ok && ae.Reason == activity.ALREADY_EXISTS {
+			return utils.NewWebhookEdit(fmt.Sprintf("%v already exists in the pool", newName)), nil
+		}
+		return nil, fmt.Errorf("activity.Rename: %v", err)
+	}
+	// The rename already happened so older records keeping the old name aren't worth failing over
+	err = history.RenameActivity(ctx, c.GuildID, c.OldName, newName, cl)
+	if err != nil {
+		ctxzap.Error(ctx, fmt.Sprintf("history.RenameActivity: %v", err))
+	}
+	err = votes.RenameActivity(ctx, c.GuildID, c.OldName, newName, cl)
+	if err != nil {
+		ctxzap.Error(ctx, fmt.Sprintf("votes.RenameActivity: %v", err))
+	}
+	if wasFow {
+		err = syncSessionEvent(ctx, g, newName, cl)
+		if err != nil {
+			ctxzap.Error(ctx, fmt.Sprintf("syncSessionEvent: %v", err))
+		}
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Renamed %v to %v", c.OldName, newName)), nil
+}
+
+// inRunningPoll returns whether the activity is in the active poll or the running tournament.
+// These refer to the activity by its old name or document.
+func inRunningPoll(ctx context.Context, g *guild.Guild, name string) (bool, error) {
+	pollInfo, err := g.GetActivePoll(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("getActivePoll: %v", err)
+	}
+	if pollInfo != nil {
+		activityID := activity.GetActivityID(g.GetGuildId(), name)
+		for _, answer := range pollInfo.Answers {
+			if answer == activityID {
+				return true, nil
+			}
+		}
+		for _, entry := range pollInfo.Entries {
+			if entry.Name == name {
+				return true, nil
+			}
+		}
+	}
+	bracket, err := g.GetBracket(ctx)
+	if err != nil {
+		return false, fmt.Errorf("getBracket: %v", err)
+	}
+	if bracket != nil && (slices.Contains(bracket.Remaining, name) || slices.Contains(bracket.Advancing, name)) {
+		return true, nil
+	}
+	return false, nil
+}
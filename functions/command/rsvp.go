@@ -0,0 +1,241 @@
+package command
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/history"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Number of past flavors of the week used for attendance stats
+const ATTENDANCE_HISTORY int = 20
+
+var rsvpLabels = map[string]string{
+	history.RSVP_GOING: "✅ Going",
+	history.RSVP_MAYBE: "🤔 Maybe",
+	history.RSVP_NO:    "❌ Can't make it",
+}
+
+// postRsvp posts the RSVP message for a new flavor of the week and starts its history entry
+func postRsvp(ctx context.Context, g *guild.Guild, winner string, cl *clients.Clients) error {
+	chanID, err := g.GetPollChannel(ctx)
+	if err != nil {
+		return fmt.Errorf("getPollChannel: %v", err)
+	}
+	if chanID == nil {
+		return nil
+	}
+	s, err := cl.Discord()
+	if err != nil {
+		return fmt.Errorf("discord: %v", err)
+	}
+	record := &history.FowRecord{
+		GuildID:   g.GetGuildId(),
+		Activity:  winner,
+		StartedAt: time.Now().UTC(),
+		ChannelID: *chanID,
+		Rsvps:     map[string]string{},
+	}
+	embeds, components := buildRsvpMessage(record)
+	msg, err := s.ChannelMessageSendComplex(*chanID, &discordgo.MessageSend{
+		Embeds:     embeds,
+		Components: components,
+	})
+	if err != nil {
+		return fmt.Errorf("channelMessageSendComplex: %v", err)
+	}
+	record.MessageID = msg.ID
+	return history.RecordFow(ctx, record, cl)
+}
+
+func buildRsvpMessage(record *history.FowRecord) ([]*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embeds := []*discordgo.MessageEmbed{buildRosterEmbed(record)}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    rsvpLabels[history.RSVP_GOING],
+					Style:    discordgo.SuccessButton,
+					CustomID: fmt.Sprintf(`{"type":"rsvp-%v"}`, history.RSVP_GOING),
+				},
+				discordgo.Button{
+					Label:    rsvpLabels[history.RSVP_MAYBE],
+					Style:    discordgo.SecondaryButton,
+					CustomID: fmt.Sprintf(`{"type":"rsvp-%v"}`, history.RSVP_MAYBE),
+				},
+				discordgo.Button{
+					Label:    rsvpLabels[history.RSVP_NO],
+					Style:    discordgo.DangerButton,
+					CustomID: fmt.Sprintf(`{"type":"rsvp-%v"}`, history.RSVP_NO),
+				},
+			},
+		},
+	}
+	return embeds, components
+}
+
+func buildRosterEmbed(record *history.FowRecord) *discordgo.MessageEmbed {
+	fields := make([]*discordgo.MessageEmbedField, 0, len(rsvpLabels))
+	for _, response := range []string{history.RSVP_GOING, history.RSVP_MAYBE, history.RSVP_NO} {
+		users := record.Attendees(response)
+		slices.Sort(users)
+		mentions := make([]string, 0, len(users))
+		for _, user := range users {
+			mentions = append(mentions, fmt.Sprintf("<@%v>", user))
+		}
+		value := strings.Join(mentions, "\n")
+		if value == "" {
+			value = "-"
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("%v (%v)", rsvpLabels[response], len(users)),
+			Value:  value,
+			Inline: true,
+		})
+	}
+	description := fmt.Sprintf("%v is the flavor of the week since <t:%v:D>", record.Activity, record.StartedAt.Unix())
+	if record.SessionStart != nil {
+		description += fmt.Sprintf("\nSession: <t:%v:F>", record.SessionStart.Unix())
+	}
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Who's playing %v?", record.Activity),
+		Description: description,
+		Color:       2326507,
+		Fields:      fields,
+	}
+}
+
+type RsvpCommand struct {
+	GuildID     string
+	UserID      string
+	Response    string
+	interaction *discordgo.Interaction
+}
+
+func NewRsvpCommand(guildID, userID, response string, interaction *discordgo.Interaction) *RsvpCommand {
+	return &RsvpCommand{
+		GuildID:     guildID,
+		UserID:      userID,
+		Response:    response,
+		interaction: interaction,
+	}
+}
+
+func (c *RsvpCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	if _, ok := rsvpLabels[c.Response]; !ok || c.interaction.Message == nil {
+		return nil, fmt.Errorf("invalid rsvp: %v", c.Response)
+	}
+	record, err := history.SetRsvp(ctx, c.GuildID, c.interaction.Message.ID, c.UserID, c.Response, cl)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			components := []discordgo.MessageComponent{}
+			return &discordgo.WebhookEdit{
+				Components: &components,
+			}, nil
+		}
+		return nil, fmt.Errorf("setRsvp: %v", err)
+	}
+	embeds, components := buildRsvpMessage(record)
+	return &discordgo.WebhookEdit{
+		Embeds:     &embeds,
+		Components: &components,
+	}, nil
+}
+
+type RosterCommand struct {
+	GuildID string
+}
+
+func NewRosterCommand(guildID string) *RosterCommand {
+	return &RosterCommand{
+		GuildID: guildID,
+	}
+}
+
+func (c *RosterCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	fows, err := history.GetRecentFows(ctx, c.GuildID, 1, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getRecentFows: %v", err)
+	}
+	if len(fows) == 0 {
+		return utils.NewWebhookEdit("There is no roster yet. One is posted when a poll picks a winner"), nil
+	}
+	record := fows[0]
+	embed := buildRosterEmbed(record)
+	embed.Description += fmt.Sprintf("\n[RSVP here](https://discord.com/channels/%v/%v/%v)", record.GuildID, record.ChannelID, record.MessageID)
+	return &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	}, nil
+}
+
+// getAttendanceFields summarises who turned up to recent flavors of the week and which were the best attended
+func getAttendanceFields(ctx context.Context, guildID string, cl *clients.Clients) ([]*discordgo.MessageEmbedField, error) {
+	fows, err := history.GetRecentFows(ctx, guildID, ATTENDANCE_HISTORY, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getRecentFows: %v", err)
+	}
+	if len(fows) == 0 {
+		return []*discordgo.MessageEmbedField{}, nil
+	}
+	attended := make(map[string]int)
+	going := make(map[string]int)
+	weeks := make(map[string]int)
+	for _, record := range fows {
+		players := record.Attendees(history.RSVP_GOING)
+		for _, user := range players {
+			attended[user]++
+		}
+		going[record.Activity] += len(players)
+		weeks[record.Activity]++
+	}
+
+	users := make([]string, 0, len(attended))
+	for user := range attended {
+		users = append(users, user)
+	}
+	slices.SortFunc(users, func(a, b string) int {
+		return cmp.Or(cmp.Compare(attended[b], attended[a]), cmp.Compare(a, b))
+	})
+	userLines := make([]string, 0, 3)
+	for _, user := range users[:min(3, len(users))] {
+		userLines = append(userLines, fmt.Sprintf("<@%v> (%v of %v)", user, attended[user], len(fows)))
+	}
+
+	activities := make([]string, 0, len(weeks))
+	for name := range weeks {
+		activities = append(activities, name)
+	}
+	average := func(name string) float64 {
+		return float64(going[name]) / float64(weeks[name])
+	}
+	slices.SortFunc(activities, func(a, b string) int {
+		return cmp.Or(cmp.Compare(average(b), average(a)), cmp.Compare(a, b))
+	})
+	activityLines := make([]string, 0, 3)
+	for _, name := range activities[:min(3, len(activities))] {
+		activityLines = append(activityLines, fmt.Sprintf("%v (%.1f going)", name, average(name)))
+	}
+
+	fields := make([]*discordgo.MessageEmbedField, 0, 2)
+	if len(userLines) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("Most regular players (last %v FoWs)", len(fows)),
+			Value: strings.Join(userLines, "\n"),
+		})
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:  "Best attended",
+		Value: strings.Join(activityLines, "\n"),
+	})
+	return fields, nil
+}
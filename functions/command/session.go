@@ -0,0 +1,256 @@
+package command
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// Default length in hours of a session
+const SESSION_DURATION int = 3
+
+// Max size of an event cover image
+const MAX_COVER_SIZE int64 = 8 * 1024 * 1024
+
+// Hosts event covers are downloaded from. Only RAWG images are used as members can set any image URL
+var COVER_IMAGE_HOSTS = []string{"media.rawg.io"}
+
+var coverClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+type SessionSlotCommand struct {
+	GuildID   string
+	Day       string
+	Hour      int
+	Minute    int
+	ChannelID string
+	Duration  int
+}
+
+func NewSessionSlotCommand(guildID, day string, hour, minute int, channelID string, duration int) *SessionSlotCommand {
+	return &SessionSlotCommand{
+		GuildID:   guildID,
+		Day:       day,
+		Hour:      hour,
+		Minute:    minute,
+		ChannelID: channelID,
+		Duration:  duration,
+	}
+}
+
+func (c *SessionSlotCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	day, ok := dayLookup[c.Day]
+	if !ok {
+		return utils.NewWebhookEdit(fmt.Sprintf("Unknown day: %v", c.Day)), nil
+	}
+	if c.Duration <= 0 {
+		c.Duration = SESSION_DURATION
+	}
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	session := &guild.SessionInfo{
+		Day:       day,
+		Hour:      c.Hour,
+		Minute:    c.Minute,
+		ChannelID: c.ChannelID,
+		Duration:  c.Duration,
+	}
+	err = g.SetSession(ctx, session)
+	if err != nil {
+		return nil, fmt.Errorf("setSession: %v", err)
+	}
+	// Move the current event to the new slot
+	fow, err := g.GetFow(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getFow: %v", err)
+	}
+	if fow != nil {
+		err = syncSessionEvent(ctx, g, *fow, cl)
+		if err != nil {
+			return nil, fmt.Errorf("syncSessionEvent: %v", err)
+		}
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Sessions will be every %v at %02d:%02d UTC in <#%v>", c.Day, c.Hour, c.Minute, c.ChannelID)), nil
+}
+
+type CancelSessionCommand struct {
+	GuildID string
+	// Remove the session slot as well as cancelling the next session
+	ClearSlot bool
+}
+
+func NewCancelSessionCommand(guildID string, clearSlot bool) *CancelSessionCommand {
+	return &CancelSessionCommand{
+		GuildID:   guildID,
+		ClearSlot: clearSlot,
+	}
+}
+
+func (c *CancelSessionCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	cancelled, err := cancelSessionEvent(ctx, g, cl)
+	if err != nil {
+		return nil, fmt.Errorf("cancelSessionEvent: %v", err)
+	}
+	if c.ClearSlot {
+		err = g.ClearSession(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("clearSession: %v", err)
+		}
+		return utils.NewWebhookEdit("Sessions will no longer be scheduled"), nil
+	}
+	if !cancelled {
+		return utils.NewWebhookEdit("There is no session to cancel"), nil
+	}
+	return utils.NewWebhookEdit("Cancelled the next session"), nil
+}
+
+// nextSlotTime returns the next start of the slot after now
+func nextSlotTime(slot guild.TimeSlot, now time.Time) time.Time {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), slot.Hour, slot.Minute, 0, 0, time.UTC)
+	start = start.AddDate(0, 0, (int(slot.Day)-int(now.Weekday())+7)%7)
+	if !start.After(now) {
+		start = start.AddDate(0, 0, 7)
+	}
+	return start
+}
+
+// syncSessionEvent creates the scheduled event for the flavor of the week or updates the upcoming one
+func syncSessionEvent(ctx context.Context, g *guild.Guild, name string, cl *clients.Clients) error {
+	session, err := g.GetSession(ctx)
+	if err != nil {
+		return fmt.Errorf("getSession: %v", err)
+	}
+	if session == nil {
+		return nil
+	}
+	return syncSessionEventAt(ctx, g, session, name, nextSlotTime(session.Slot(), time.Now()), cl)
+}
+
+func syncSessionEventAt(ctx context.Context, g *guild.Guild, session *guild.SessionInfo, name string, start time.Time, cl *clients.Clients) error {
+	eventID, err := g.GetSessionEvent(ctx)
+	if err != nil {
+		return fmt.Errorf("getSessionEvent: %v", err)
+	}
+	s, err := cl.Discord()
+	if err != nil {
+		return fmt.Errorf("discord: %v", err)
+	}
+
+	end := start.Add(time.Duration(session.Duration) * time.Hour)
+	params := &discordgo.GuildScheduledEventParams{
+		ChannelID:          session.ChannelID,
+		Name:               name,
+		Description:        fmt.Sprintf("%v is the flavor of the week", name),
+		ScheduledStartTime: &start,
+		ScheduledEndTime:   &end,
+		PrivacyLevel:       discordgo.GuildScheduledEventPrivacyLevelGuildOnly,
+		EntityType:         discordgo.GuildScheduledEventEntityTypeVoice,
+	}
+	act, err := activity.GetActivity(ctx, name, g.GetGuildId(), cl)
+	if err == nil && act.GameInfo() != nil && act.GameInfo().BackgroundImage != "" {
+		params.Image, err = fetchCoverImage(ctx, act.GameInfo().BackgroundImage)
+		if err != nil {
+			ctxzap.Warn(ctx, fmt.Sprintf("fetchCoverImage: %v", err))
+		}
+	}
+
+	if eventID != "" {
+		event, err := s.GuildScheduledEvent(g.GetGuildId(), eventID, false)
+		if err == nil && event.Status == discordgo.GuildScheduledEventStatusScheduled {
+			ctxzap.Info(ctx, fmt.Sprintf("Updating scheduled event %v for %v", eventID, name))
+			_, err = s.GuildScheduledEventEdit(g.GetGuildId(), eventID, params)
+			if err != nil {
+				return fmt.Errorf("guildScheduledEventEdit: %v", err)
+			}
+			return nil
+		}
+	}
+	ctxzap.Info(ctx, fmt.Sprintf("Creating scheduled event for %v at %v", name, start))
+	event, err := s.GuildScheduledEventCreate(g.GetGuildId(), params)
+	if err != nil {
+		return fmt.Errorf("guildScheduledEventCreate: %v", err)
+	}
+	return g.SetSessionEvent(ctx, event.ID)
+}
+
+// cancelSessionEvent cancels the upcoming scheduled event. Returns false if there wasn't one
+func cancelSessionEvent(ctx context.Context, g *guild.Guild, cl *clients.Clients) (bool, error) {
+	eventID, err := g.GetSessionEvent(ctx)
+	if err != nil {
+		return false, fmt.Errorf("getSessionEvent: %v", err)
+	}
+	if eventID == "" {
+		return false, nil
+	}
+	s, err := cl.Discord()
+	if err != nil {
+		return false, fmt.Errorf("discord: %v", err)
+	}
+	cancelled := false
+	event, err := s.GuildScheduledEvent(g.GetGuildId(), eventID, false)
+	if err == nil && event.Status == discordgo.GuildScheduledEventStatusScheduled {
+		_, err = s.GuildScheduledEventEdit(g.GetGuildId(), eventID, &discordgo.GuildScheduledEventParams{
+			Status: discordgo.GuildScheduledEventStatusCanceled,
+		})
+		if err != nil {
+			return false, fmt.Errorf("guildScheduledEventEdit: %v", err)
+		}
+		cancelled = true
+	}
+	return cancelled, g.SetSessionEvent(ctx, "")
+}
+
+// fetchCoverImage downloads an image as a data URI for use as an event cover
+func fetchCoverImage(ctx context.Context, imageURL string) (string, error) {
+	parsed, err := url.Parse(imageURL)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "https" || !slices.Contains(COVER_IMAGE_HOSTS, parsed.Hostname()) {
+		return "", fmt.Errorf("image host not allowed: %v", parsed.Host)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := coverClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %v", resp.Status)
+	}
+	if resp.ContentLength > MAX_COVER_SIZE {
+		return "", fmt.Errorf("image too large: %v bytes", resp.ContentLength)
+	}
+	// Read one byte past the limit to tell a large image from one of exactly the max size
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MAX_COVER_SIZE+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > MAX_COVER_SIZE {
+		return "", fmt.Errorf("image larger than %v bytes", MAX_COVER_SIZE)
+	}
+	return fmt.Sprintf("data:%v;base64,%v", http.DetectContentType(data), base64.StdEncoding.EncodeToString(data)), nil
+}
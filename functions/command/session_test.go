@@ -0,0 +1,31 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+)
+
+func TestNextSlotTime(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2024, time.June, 5, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		slot guild.TimeSlot
+		now  time.Time
+		want time.Time
+	}{
+		{guild.TimeSlot{Day: time.Wednesday, Hour: 18}, now, time.Date(2024, time.June, 5, 18, 0, 0, 0, time.UTC)},
+		{guild.TimeSlot{Day: time.Wednesday, Hour: 12}, now, time.Date(2024, time.June, 12, 12, 0, 0, 0, time.UTC)},
+		{guild.TimeSlot{Day: time.Wednesday, Hour: 9}, now, time.Date(2024, time.June, 12, 9, 0, 0, 0, time.UTC)},
+		{guild.TimeSlot{Day: time.Friday, Hour: 20, Minute: 30}, now, time.Date(2024, time.June, 7, 20, 30, 0, 0, time.UTC)},
+		{guild.TimeSlot{Day: time.Monday, Hour: 8}, now, time.Date(2024, time.June, 10, 8, 0, 0, 0, time.UTC)},
+		// Tuesday 23:00 in UTC
+		{guild.TimeSlot{Day: time.Wednesday, Hour: 0}, time.Date(2024, time.June, 5, 1, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), time.Date(2024, time.June, 5, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		if got := nextSlotTime(test.slot, test.now); !got.Equal(test.want) {
+			t.Errorf(`nextSlotTime(%v, %v) = %v, want %v`, test.slot, test.now, got, test.want)
+		}
+	}
+}
@@ -51,26 +51,43 @@ func (c *StatsCommand) Execute(ctx context.Context, cl *clients.Clients) (*disco
 	if err != nil {
 		return nil, fmt.Errorf("GetPoolSize: %v", err)
 	}
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:  "Flavor of the Week",
+			Value: *fow,
+		},
+		{
+			Name:   "# of FoWs",
+			Value:  fmt.Sprint(numFow),
+			Inline: true,
+		},
+		{
+			Name:   "Pool size",
+			Value:  fmt.Sprint(poolSize),
+			Inline: true,
+		},
+	}
+	turnout, polls, err := getTurnout(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getTurnout: %v", err)
+	}
+	if polls > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("Avg turnout (last %v polls)", polls),
+			Value:  fmt.Sprintf("%.1f voters", turnout),
+			Inline: true,
+		})
+	}
+	attendance, err := getAttendanceFields(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getAttendanceFields: %v", err)
+	}
+	fields = append(fields, attendance...)
 	return &discordgo.WebhookEdit{
 		Embeds: &[]*discordgo.MessageEmbed{
 			{
-				Title: guildInfo.Name,
-				Fields: []*discordgo.MessageEmbedField{
-					{
-						Name:  "Flavor of the Week",
-						Value: *fow,
-					},
-					{
-						Name:   "# of FoWs",
-						Value:  fmt.Sprint(numFow),
-						Inline: true,
-					},
-					{
-						Name:   "Pool size",
-						Value:  fmt.Sprint(poolSize),
-						Inline: true,
-					},
-				},
+				Title:  guildInfo.Name,
+				Fields: fields,
 			},
 		},
 	}, nil
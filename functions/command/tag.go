@@ -0,0 +1,138 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/activity"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/elliotchance/orderedmap/v2"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// Max number of tags on a single activity
+const MAX_TAGS int = 15
+
+const THEMED_EMOJI = "🏷️"
+
+type TagCommand struct {
+	GuildID string
+	Name    string
+	Tags    string
+	Remove  bool
+}
+
+func NewTagCommand(guildID, name, tags string, remove bool) *TagCommand {
+	return &TagCommand{
+		GuildID: guildID,
+		Name:    name,
+		Tags:    tags,
+		Remove:  remove,
+	}
+}
+
+func (c *TagCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	tags := activity.NormalizeTags(c.Tags)
+	if len(tags) == 0 {
+		return utils.NewWebhookEdit("No tags were given"), nil
+	}
+	act, err := activity.GetActivity(ctx, c.Name, c.GuildID, cl)
+	if err != nil {
+		ae, ok := err.(*activity.ActivityError)
+		if ok && ae.Reason == activity.DOES_NOT_EXIST {
+			return utils.NewWebhookEdit(fmt.Sprintf("%v does not exist", c.Name)), nil
+		}
+		return nil, fmt.Errorf("getActivity: %v", err)
+	}
+	if c.Remove {
+		err = act.RemoveTags(ctx, tags)
+		if err != nil {
+			return nil, fmt.Errorf("removeTags: %v", err)
+		}
+	} else {
+		added := slices.DeleteFunc(slices.Clone(tags), func(tag string) bool {
+			return slices.Contains(act.Tags(), tag)
+		})
+		if len(act.Tags())+len(added) > MAX_TAGS {
+			return utils.NewWebhookEdit(fmt.Sprintf("An activity can have at most %v tags", MAX_TAGS)), nil
+		}
+		err = act.AddTags(ctx, tags)
+		if err != nil {
+			return nil, fmt.Errorf("addTags: %v", err)
+		}
+	}
+	if len(act.Tags()) == 0 {
+		return utils.NewWebhookEdit(fmt.Sprintf("%v has no tags", act.Name())), nil
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("%v tags: %v", act.Name(), strings.Join(act.Tags(), ", "))), nil
+}
+
+type ThemedPollCommand struct {
+	GuildID string
+	Tag     string
+}
+
+func NewThemedPollCommand(guildID, tag string) *ThemedPollCommand {
+	return &ThemedPollCommand{
+		GuildID: guildID,
+		Tag:     tag,
+	}
+}
+
+// Execute starts a poll drawn only from activities with the tag. Tagged nominations are used first.
+func (c *ThemedPollCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	tags := activity.NormalizeTags(c.Tag)
+	if len(tags) != 1 {
+		return utils.NewWebhookEdit("A themed poll needs a single tag"), nil
+	}
+	tag := tags[0]
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+
+	nominations := slotProvider{
+		emoji: NOMINATION_EMOJI,
+		candidates: func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error) {
+			nominated, err := activity.GetNominatedActivities(ctx, g.GetGuildId(), cl)
+			if err != nil {
+				return nil, fmt.Errorf("getNominatedActivities: %v", err)
+			}
+			nominated = slices.DeleteFunc(nominated, func(act activity.NominatedActivity) bool {
+				return !slices.Contains(act.Tags, tag)
+			})
+			return roundRobinNominations(nominated, nil), nil
+		},
+	}
+	random := slotProvider{
+		emoji:  THEMED_EMOJI,
+		random: true,
+		candidates: func(ctx context.Context, g *guild.Guild, n int, cl *clients.Clients) ([]string, error) {
+			return activity.GetRandomActivities(ctx, g.GetGuildId(), n, &activity.RandomOptions{Tag: tag}, cl)
+		},
+	}
+
+	answers := orderedmap.NewOrderedMap[string, answerEntry]()
+	err = fillFromProvider(ctx, g, NOMINATIONS_PROVIDER, nominations, MAX_POLL_ENTRIES, answers, cl)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", NOMINATIONS_PROVIDER, err)
+	}
+	err = fillFromProvider(ctx, g, RANDOM_PROVIDER, random, MAX_POLL_ENTRIES-answers.Len(), answers, cl)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", RANDOM_PROVIDER, err)
+	}
+	if answers.Len() < 2 {
+		return utils.NewWebhookEdit(fmt.Sprintf("Not enough activities are tagged %v for a poll", tag)), nil
+	}
+	err = applyActivityEmojis(ctx, g, answers, cl)
+	if err != nil {
+		return nil, fmt.Errorf("applyActivityEmojis: %v", err)
+	}
+	ctxzap.Info(ctx, fmt.Sprintf("Generated themed poll entries for %v: %v", tag, answers))
+	return NewCreatePollCommand(c.GuildID, answersToPollEntries(answers), 48, false).Execute(ctx, cl)
+}
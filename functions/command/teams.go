@@ -0,0 +1,214 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/history"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+const MAX_TEAMS int = 10
+
+// Number of shuffles tried when avoiding last session's pairings
+const TEAM_SHUFFLE_ATTEMPTS int = 50
+
+type TeamsCommand struct {
+	GuildID string
+	Teams   int
+	// Voice channel whose members are teamed up. Everyone going is used when empty
+	ChannelID string
+	// Only team up the members of the voice channel who are going
+	GoingOnly    bool
+	AvoidRepeats bool
+}
+
+func NewTeamsCommand(guildID string, teams int, channelID string, goingOnly, avoidRepeats bool) *TeamsCommand {
+	return &TeamsCommand{
+		GuildID:      guildID,
+		Teams:        teams,
+		ChannelID:    channelID,
+		GoingOnly:    goingOnly,
+		AvoidRepeats: avoidRepeats,
+	}
+}
+
+func (c *TeamsCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	if c.Teams < 2 || c.Teams > MAX_TEAMS {
+		return utils.NewWebhookEdit(fmt.Sprintf("The number of teams must be between 2 and %v", MAX_TEAMS)), nil
+	}
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+
+	var players []string
+	var source string
+	if c.ChannelID != "" && !c.GoingOnly {
+		s, err := cl.Discord()
+		if err != nil {
+			return nil, fmt.Errorf("discord: %v", err)
+		}
+		members, err := getRoleMembers(s, c.GuildID, "")
+		if err != nil {
+			return nil, fmt.Errorf("getRoleMembers: %v", err)
+		}
+		players, err = filterVoiceChannelMembers(c.GuildID, c.ChannelID, members, cl)
+		if err != nil {
+			return nil, fmt.Errorf("filterVoiceChannelMembers: %v", err)
+		}
+		source = fmt.Sprintf("Players in <#%v>", c.ChannelID)
+	} else {
+		fows, err := history.GetRecentFows(ctx, c.GuildID, 1, cl)
+		if err != nil {
+			return nil, fmt.Errorf("getRecentFows: %v", err)
+		}
+		if len(fows) == 0 {
+			return utils.NewWebhookEdit("There is no roster yet. Pick a voice channel instead"), nil
+		}
+		players = fows[0].Attendees(history.RSVP_GOING)
+		source = fmt.Sprintf("Players going to %v", fows[0].Activity)
+		if c.ChannelID != "" {
+			players, err = filterVoiceChannelMembers(c.GuildID, c.ChannelID, players, cl)
+			if err != nil {
+				return nil, fmt.Errorf("filterVoiceChannelMembers: %v", err)
+			}
+			source = fmt.Sprintf("Players going to %v in <#%v>", fows[0].Activity, c.ChannelID)
+		}
+	}
+	if len(players) < c.Teams {
+		return utils.NewWebhookEdit(fmt.Sprintf("There are %v players. At least %v are needed for %v teams", len(players), c.Teams, c.Teams)), nil
+	}
+
+	var lastTeams []guild.Team
+	if c.AvoidRepeats {
+		lastTeams, err = g.GetLastTeams(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("getLastTeams: %v", err)
+		}
+	}
+	teams, repeats := buildTeams(players, c.Teams, lastTeams)
+	err = g.SetLastTeams(ctx, teams)
+	if err != nil {
+		return nil, fmt.Errorf("setLastTeams: %v", err)
+	}
+
+	fields := make([]*discordgo.MessageEmbedField, 0, len(teams))
+	for i, team := range teams {
+		mentions := make([]string, 0, len(team.Members))
+		for _, user := range team.Members {
+			mentions = append(mentions, fmt.Sprintf("<@%v>", user))
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("Team %v", i+1),
+			Value:  strings.Join(mentions, "\n"),
+			Inline: true,
+		})
+	}
+	description := source
+	if c.AvoidRepeats && len(lastTeams) > 0 {
+		description += fmt.Sprintf("\n%v pairings repeated from last time", repeats)
+	}
+	return &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{
+			{
+				Title:       "Teams",
+				Description: description,
+				Color:       2326507,
+				Fields:      fields,
+			},
+		},
+	}, nil
+}
+
+// buildTeams deals shuffled players into n teams whose sizes differ by at most 1.
+// When lastTeams is set the shuffle with the fewest repeated pairings is kept
+func buildTeams(players []string, n int, lastTeams []guild.Team) ([]guild.Team, int) {
+	lastPairs := make(map[[2]string]bool)
+	for _, team := range lastTeams {
+		for _, pair := range teamPairs(team.Members) {
+			lastPairs[pair] = true
+		}
+	}
+	attempts := 1
+	if len(lastPairs) > 0 {
+		attempts = TEAM_SHUFFLE_ATTEMPTS
+	}
+
+	var best []guild.Team
+	bestRepeats := -1
+	shuffled := slices.Clone(players)
+	for range attempts {
+		rand.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		teams := make([]guild.Team, n)
+		for i, player := range shuffled {
+			teams[i%n].Members = append(teams[i%n].Members, player)
+		}
+		repeats := 0
+		for _, team := range teams {
+			for _, pair := range teamPairs(team.Members) {
+				if lastPairs[pair] {
+					repeats++
+				}
+			}
+		}
+		if bestRepeats < 0 || repeats < bestRepeats {
+			best, bestRepeats = teams, repeats
+		}
+		if bestRepeats == 0 {
+			break
+		}
+	}
+	return best, bestRepeats
+}
+
+func teamPairs(members []string) [][2]string {
+	pairs := make([][2]string, 0)
+	for i, a := range members {
+		for _, b := range members[i+1:] {
+			pairs = append(pairs, [2]string{min(a, b), max(a, b)})
+		}
+	}
+	return pairs
+}
+
+// filterVoiceChannelMembers returns the users connected to a voice channel.
+// Voice states are only sent over the gateway, so each user's state is looked up through the API
+func filterVoiceChannelMembers(guildID, channelID string, users []string, cl *clients.Clients) ([]string, error) {
+	s, err := cl.Discord()
+	if err != nil {
+		return nil, fmt.Errorf("discord: %v", err)
+	}
+	inChannel := make([]string, 0, len(users))
+	for _, user := range users {
+		endpoint := discordgo.EndpointGuild(guildID) + "/voice-states/" + user
+		body, err := s.RequestWithBucketID(http.MethodGet, endpoint, nil, discordgo.EndpointGuild(guildID)+"/voice-states/")
+		if err != nil {
+			var restErr *discordgo.RESTError
+			if errors.As(err, &restErr) && restErr.Response.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return nil, fmt.Errorf("voiceState: %v", err)
+		}
+		var state discordgo.VoiceState
+		err = json.Unmarshal(body, &state)
+		if err != nil {
+			return nil, fmt.Errorf("unmarshal: %v", err)
+		}
+		if state.ChannelID == channelID {
+			inChannel = append(inChannel, user)
+		}
+	}
+	return inChannel, nil
+}
@@ -0,0 +1,224 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/history"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// Length in hours of the session time poll
+const TIME_POLL_DURATION int = 24
+
+const TIME_SLOT_EMOJI = "🕒"
+
+type TimePollCommand struct {
+	GuildID string
+	Slots   string
+}
+
+func NewTimePollCommand(guildID, slots string) *TimePollCommand {
+	return &TimePollCommand{
+		GuildID: guildID,
+		Slots:   slots,
+	}
+}
+
+func (c *TimePollCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	slots, err := parseTimeSlots(c.Slots)
+	if err != nil {
+		return utils.NewWebhookEdit(fmt.Sprintf("Invalid time slots: %v\nUse slots like \"Saturday 20:00, Sunday 18:30\"", err)), nil
+	}
+	if len(slots) > MAX_POLL_ENTRIES {
+		return utils.NewWebhookEdit(fmt.Sprintf("There can be at most %v time slots", MAX_POLL_ENTRIES)), nil
+	}
+	err = g.SetTimeSlots(ctx, slots)
+	if err != nil {
+		return nil, fmt.Errorf("setTimeSlots: %v", err)
+	}
+	if len(slots) < 2 {
+		return utils.NewWebhookEdit("Session times will not be polled"), nil
+	}
+	labels := make([]string, 0, len(slots))
+	for _, slot := range slots {
+		labels = append(labels, slot.String())
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("After each flavor of the week is picked, members will vote on a time:\n%v", strings.Join(labels, "\n"))), nil
+}
+
+// parseTimeSlots parses a comma separated list of slots such as "Saturday 20:00"
+func parseTimeSlots(text string) ([]guild.TimeSlot, error) {
+	slots := make([]guild.TimeSlot, 0)
+	for _, field := range strings.Split(text, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		dayText, timeText, found := strings.Cut(field, " ")
+		if !found {
+			return nil, fmt.Errorf("missing time in %v", field)
+		}
+		day := -1
+		for name, weekday := range dayLookup {
+			if len(dayText) >= 3 && strings.HasPrefix(strings.ToLower(name), strings.ToLower(dayText)) {
+				day = int(weekday)
+			}
+		}
+		if day < 0 {
+			return nil, fmt.Errorf("unknown day: %v", dayText)
+		}
+		hourText, minuteText, _ := strings.Cut(strings.TrimSpace(timeText), ":")
+		hour, err := strconv.Atoi(hourText)
+		if err != nil || hour < 0 || hour > 23 {
+			return nil, fmt.Errorf("invalid hour in %v", field)
+		}
+		minute := 0
+		if minuteText != "" {
+			minute, err = strconv.Atoi(minuteText)
+			if err != nil || minute < 0 || minute > 59 {
+				return nil, fmt.Errorf("invalid minute in %v", field)
+			}
+		}
+		slot := guild.TimeSlot{
+			Day:    time.Weekday(day),
+			Hour:   hour,
+			Minute: minute,
+		}
+		if !slices.Contains(slots, slot) {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
+
+// startTimePoll opens the poll for when to play the new flavor of the week
+func startTimePoll(ctx context.Context, g *guild.Guild, winner string, cl *clients.Clients) error {
+	slots, err := g.GetTimeSlots(ctx)
+	if err != nil {
+		return fmt.Errorf("getTimeSlots: %v", err)
+	}
+	if len(slots) < 2 {
+		return nil
+	}
+	entries := make([]guild.PollEntry, 0, len(slots))
+	for _, slot := range slots {
+		entries = append(entries, guild.PollEntry{
+			Name:  slot.String(),
+			Emoji: TIME_SLOT_EMOJI,
+		})
+	}
+	pollCmd := NewCreatePollCommand(g.GetGuildId(), entries, TIME_POLL_DURATION, false)
+	pollCmd.SetTimePoll(winner)
+	_, err = pollCmd.Execute(ctx, cl)
+	return err
+}
+
+// closeTimePoll ends a running session time poll with the votes so far so it doesn't hold up the next poll.
+// Any other active poll is returned unchanged
+func closeTimePoll(ctx context.Context, guildID string, pollInfo *guild.PollInfo, cl *clients.Clients) (*guild.PollInfo, error) {
+	if pollInfo == nil || !pollInfo.TimePoll {
+		return pollInfo, nil
+	}
+	ctxzap.Info(ctx, "Closing the time poll for a new poll")
+	_, err := NewEndPollCommand(guildID).Execute(ctx, cl)
+	if err != nil {
+		return nil, fmt.Errorf("endPoll: %v", err)
+	}
+	return nil, nil
+}
+
+// endTimePoll stores the winning time slot with the flavor of the week and announces it
+func endTimePoll(ctx context.Context, s *discordgo.Session, g *guild.Guild, pollInfo *guild.PollInfo, result *pollResult, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	err := g.ClearTimePoll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("clearTimePoll: %v", err)
+	}
+	if result.voterCount == 0 {
+		_, err = s.ChannelMessageSendComplex(pollInfo.ChannelID, &discordgo.MessageSend{
+			Embeds: []*discordgo.MessageEmbed{
+				{
+					Title:       fmt.Sprintf("%v Session time", TIME_SLOT_EMOJI),
+					Description: "Nobody voted on a session time",
+					Color:       2326507,
+				},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("channelMessageSendComplex: %v", err)
+		}
+		return utils.NewWebhookEdit("Time poll ended without votes"), nil
+	}
+	slots, err := g.GetTimeSlots(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getTimeSlots: %v", err)
+	}
+	// Ties go to the slot listed first
+	i := slices.IndexFunc(slots, func(slot guild.TimeSlot) bool {
+		return slices.Contains(result.winners, slot.String())
+	})
+	if i < 0 {
+		return utils.NewWebhookEdit("The winning time slot is no longer configured"), nil
+	}
+	slot := slots[i]
+	start := nextSlotTime(slot, time.Now())
+
+	fow, err := g.GetFow(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getFow: %v", err)
+	}
+	name := "the flavor of the week"
+	if fow != nil {
+		name = *fow
+	}
+	fows, err := history.GetRecentFows(ctx, g.GetGuildId(), 1, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getRecentFows: %v", err)
+	}
+	if len(fows) > 0 {
+		err = history.SetSession(ctx, fows[0], slot.String(), start, cl)
+		if err != nil {
+			return nil, fmt.Errorf("setSession: %v", err)
+		}
+	}
+	session, err := g.GetSession(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getSession: %v", err)
+	}
+	if session != nil && fow != nil {
+		err = syncSessionEventAt(ctx, g, session, *fow, start, cl)
+		if err != nil {
+			ctxzap.Error(ctx, fmt.Sprintf("syncSessionEventAt: %v", err))
+		}
+	}
+
+	description := fmt.Sprintf("We're playing %v <t:%v:F>", name, start.Unix())
+	if result.tie {
+		description = fmt.Sprintf("The vote was tied. We're playing %v <t:%v:F>", name, start.Unix())
+	}
+	_, err = s.ChannelMessageSendComplex(pollInfo.ChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{
+			{
+				Title:       fmt.Sprintf("%v Session time", TIME_SLOT_EMOJI),
+				Description: description,
+				Color:       2326507,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("channelMessageSendComplex: %v", err)
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Time poll ended\nSession: %v", slot)), nil
+}
@@ -0,0 +1,38 @@
+package command
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+)
+
+func TestParseTimeSlots(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []guild.TimeSlot
+		wantErr bool
+	}{
+		{"Saturday 20:00, sun 18:30", []guild.TimeSlot{{Day: time.Saturday, Hour: 20}, {Day: time.Sunday, Hour: 18, Minute: 30}}, false},
+		{"fri 9", []guild.TimeSlot{{Day: time.Friday, Hour: 9}}, false},
+		{"sat 20:00, Saturday 20:00", []guild.TimeSlot{{Day: time.Saturday, Hour: 20}}, false},
+		{"", []guild.TimeSlot{}, false},
+		{"saturday", nil, true},
+		{"sa 20:00", nil, true},
+		{"someday 20:00", nil, true},
+		{"sat 24:00", nil, true},
+		{"sat 20:60", nil, true},
+		{"sat x", nil, true},
+	}
+	for _, test := range tests {
+		got, err := parseTimeSlots(test.text)
+		if (err != nil) != test.wantErr {
+			t.Errorf(`parseTimeSlots(%q) err = %v, want error %v`, test.text, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && !slices.Equal(got, test.want) {
+			t.Errorf(`parseTimeSlots(%q) = %v, want %v`, test.text, got, test.want)
+		}
+	}
+}
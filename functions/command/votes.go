@@ -0,0 +1,353 @@
+package command
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/guild"
+	"github.com/PinkNoize/flavor-of-the-week/functions/utils"
+	"github.com/PinkNoize/flavor-of-the-week/functions/votes"
+	"github.com/bwmarrin/discordgo"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+)
+
+// Max number of voters returned per request
+const VOTERS_PAGE_SIZE int = 100
+
+// Number of past polls used for vote stats
+const VOTE_HISTORY int = 50
+
+// Number of past polls whose voters are reminded to vote
+const REMINDER_HISTORY int = 5
+
+// How long before a poll closes non-voters are reminded
+const REMINDER_WINDOW time.Duration = 12 * time.Hour
+
+// getPollVoters returns the IDs of the users that voted for each answer of the poll
+func getPollVoters(s *discordgo.Session, channelID, messageID string, poll *discordgo.Poll) (map[int][]string, error) {
+	voters := make(map[int][]string)
+	for _, ans := range poll.Answers {
+		answerVoters := make([]string, 0)
+		after := ""
+		for {
+			params := url.Values{}
+			params.Set("limit", strconv.Itoa(VOTERS_PAGE_SIZE))
+			if after != "" {
+				params.Set("after", after)
+			}
+			endpoint := discordgo.EndpointPollAnswerVoters(channelID, messageID, ans.AnswerID)
+			body, err := s.RequestWithBucketID("GET", endpoint+"?"+params.Encode(), nil, endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("pollAnswerVoters: %v", err)
+			}
+			var page struct {
+				Users []*discordgo.User `json:"users"`
+			}
+			err = discordgo.Unmarshal(body, &page)
+			if err != nil {
+				return nil, fmt.Errorf("unmarshal: %v", err)
+			}
+			for _, user := range page.Users {
+				answerVoters = append(answerVoters, user.ID)
+			}
+			if len(page.Users) < VOTERS_PAGE_SIZE {
+				break
+			}
+			after = page.Users[len(page.Users)-1].ID
+		}
+		voters[ans.AnswerID] = answerVoters
+	}
+	return voters, nil
+}
+
+func countVoters(voters map[int][]string) int {
+	unique := make(map[string]bool)
+	for _, users := range voters {
+		for _, user := range users {
+			unique[user] = true
+		}
+	}
+	return len(unique)
+}
+
+// tallyVotes counts the votes for each name
+func tallyVotes(voters map[string][]string) map[string]int {
+	results := make(map[string]int)
+	for _, votedFor := range voters {
+		for _, name := range votedFor {
+			results[name]++
+		}
+	}
+	return results
+}
+
+// mostVoted returns the candidates with the most votes
+func mostVoted(results map[string]int, candidates []string) []string {
+	most := 0
+	for _, name := range candidates {
+		most = max(most, results[name])
+	}
+	winners := make([]string, 0)
+	for _, name := range candidates {
+		// With no votes every candidate is tied
+		if results[name] == most {
+			winners = append(winners, name)
+		}
+	}
+	return winners
+}
+
+// recordPollVotes stores who voted for what, leaving out users that opted out
+func recordPollVotes(ctx context.Context, g *guild.Guild, pollInfo *guild.PollInfo, voters map[string][]string, voterCount int, winners []string, cl *clients.Clients) error {
+	optOut, err := g.GetVoteTrackingOptOut(ctx)
+	if err != nil {
+		return fmt.Errorf("getVoteTrackingOptOut: %v", err)
+	}
+	userVotes := make(map[string][]string)
+	for user, names := range voters {
+		if !optOut[user] {
+			userVotes[user] = names
+		}
+	}
+	return votes.RecordPoll(ctx, &votes.PollRecord{
+		GuildID:    g.GetGuildId(),
+		MessageID:  pollInfo.MessageID,
+		EndedAt:    time.Now().UTC(),
+		Winners:    winners,
+		VoterCount: voterCount,
+		Voters:     userVotes,
+	}, cl)
+}
+
+type MyVotesCommand struct {
+	GuildID  string
+	UserID   string
+	Tracking *bool
+}
+
+func NewMyVotesCommand(guildID, userID string, tracking *bool) *MyVotesCommand {
+	return &MyVotesCommand{
+		GuildID:  guildID,
+		UserID:   userID,
+		Tracking: tracking,
+	}
+}
+
+func (c *MyVotesCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	if c.Tracking != nil {
+		err = g.SetVoteTracking(ctx, c.UserID, *c.Tracking)
+		if err != nil {
+			return nil, fmt.Errorf("setVoteTracking: %v", err)
+		}
+		if *c.Tracking {
+			return utils.NewWebhookEdit("Your votes will be recorded from the next poll"), nil
+		}
+		err = votes.RemoveVoter(ctx, c.GuildID, c.UserID, cl)
+		if err != nil {
+			return nil, fmt.Errorf("removeVoter: %v", err)
+		}
+		return utils.NewWebhookEdit("Your votes will no longer be recorded and your past votes have been deleted"), nil
+	}
+
+	optOut, err := g.GetVoteTrackingOptOut(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getVoteTrackingOptOut: %v", err)
+	}
+	if optOut[c.UserID] {
+		return utils.NewWebhookEdit("You have opted out of vote tracking. Use */my-votes tracking:True* to opt back in"), nil
+	}
+	history, err := votes.GetRecentPolls(ctx, c.GuildID, VOTE_HISTORY, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getRecentPolls: %v", err)
+	}
+
+	voted, won := 0, 0
+	picks := make(map[string]int)
+	for _, record := range history {
+		userPicks, ok := record.Voters[c.UserID]
+		if !ok {
+			continue
+		}
+		voted++
+		if slices.ContainsFunc(userPicks, func(pick string) bool {
+			return slices.Contains(record.Winners, pick)
+		}) {
+			won++
+		}
+		for _, pick := range userPicks {
+			if pick != REROLL_ANSWER {
+				picks[pick]++
+			}
+		}
+	}
+	if voted == 0 {
+		return utils.NewWebhookEdit("You haven't voted in any recent polls"), nil
+	}
+
+	topPicks := make([]string, 0, len(picks))
+	for pick := range picks {
+		topPicks = append(topPicks, pick)
+	}
+	slices.SortFunc(topPicks, func(a, b string) int {
+		if n := cmp.Compare(picks[b], picks[a]); n != 0 {
+			return n
+		}
+		return strings.Compare(a, b)
+	})
+	if len(topPicks) > 3 {
+		topPicks = topPicks[:3]
+	}
+	for i, pick := range topPicks {
+		topPicks[i] = fmt.Sprintf("%v (%v)", pick, picks[pick])
+	}
+	fields := []*discordgo.MessageEmbedField{
+		{
+			Name:   "Polls voted in",
+			Value:  fmt.Sprintf("%v of %v", voted, len(history)),
+			Inline: true,
+		},
+		{
+			Name:   "Picks won",
+			Value:  fmt.Sprintf("%v (%v%%)", won, won*100/voted),
+			Inline: true,
+		},
+	}
+	if len(topPicks) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  "Most voted for",
+			Value: strings.Join(topPicks, "\n"),
+		})
+	}
+	return &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{
+			{
+				Title:  "Your votes",
+				Fields: fields,
+			},
+		},
+	}, nil
+}
+
+// getTurnout returns the average number of voters over the guild's recent polls
+func getTurnout(ctx context.Context, guildID string, cl *clients.Clients) (float64, int, error) {
+	history, err := votes.GetRecentPolls(ctx, guildID, VOTE_HISTORY, cl)
+	if err != nil {
+		return 0, 0, fmt.Errorf("getRecentPolls: %v", err)
+	}
+	if len(history) == 0 {
+		return 0, 0, nil
+	}
+	total := 0
+	for _, record := range history {
+		total += record.VoterCount
+	}
+	return float64(total) / float64(len(history)), len(history), nil
+}
+
+type RemindVotersCommand struct {
+	GuildID string
+}
+
+func NewRemindVotersCommand(guildID string) *RemindVotersCommand {
+	return &RemindVotersCommand{
+		GuildID: guildID,
+	}
+}
+
+// Execute reminds recent voters who haven't voted in the active poll before it closes
+func (c *RemindVotersCommand) Execute(ctx context.Context, cl *clients.Clients) (*discordgo.WebhookEdit, error) {
+	g, err := guild.GetGuild(ctx, c.GuildID, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getGuild: %v", err)
+	}
+	pollInfo, err := g.GetActivePoll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getActivePoll: %v", err)
+	}
+	if pollInfo == nil || pollInfo.SuddenDeath || pollInfo.Reminded {
+		return utils.NewWebhookEdit("No poll to send reminders for"), nil
+	}
+	s, err := cl.Discord()
+	if err != nil {
+		return nil, fmt.Errorf("discord: %v", err)
+	}
+	msg, err := s.ChannelMessage(pollInfo.ChannelID, pollInfo.MessageID)
+	if err != nil {
+		return nil, fmt.Errorf("channelMessage: %v", err)
+	}
+	voted := make(map[string]bool)
+	var closesAt *time.Time
+	if pollInfo.IsComponentPoll() {
+		closesAt = pollInfo.ClosesAt
+		for user := range castBallots(pollInfo) {
+			voted[user] = true
+		}
+	} else if msg.Poll != nil {
+		closesAt = msg.Poll.Expiry
+	}
+	if closesAt == nil || time.Until(*closesAt) > REMINDER_WINDOW {
+		return utils.NewWebhookEdit("Poll is not closing soon"), nil
+	}
+	if !pollInfo.IsComponentPoll() {
+		voters, err := getPollVoters(s, pollInfo.ChannelID, pollInfo.MessageID, msg.Poll)
+		if err != nil {
+			return nil, fmt.Errorf("getPollVoters: %v", err)
+		}
+		for _, users := range voters {
+			for _, user := range users {
+				voted[user] = true
+			}
+		}
+	}
+	optOut, err := g.GetVoteTrackingOptOut(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getVoteTrackingOptOut: %v", err)
+	}
+	history, err := votes.GetRecentPolls(ctx, c.GuildID, REMINDER_HISTORY, cl)
+	if err != nil {
+		return nil, fmt.Errorf("getRecentPolls: %v", err)
+	}
+	missing := make([]string, 0)
+	for _, record := range history {
+		for user := range record.Voters {
+			if !voted[user] && !optOut[user] && !slices.Contains(missing, user) {
+				missing = append(missing, user)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		mentions := make([]string, 0, len(missing))
+		for _, user := range missing {
+			mentions = append(mentions, fmt.Sprintf("<@%v>", user))
+		}
+		_, err = s.ChannelMessageSendComplex(pollInfo.ChannelID, &discordgo.MessageSend{
+			Content: fmt.Sprintf("⏰ The poll closes <t:%v:R> and you haven't voted yet: %v", closesAt.Unix(), strings.Join(mentions, " ")),
+			AllowedMentions: &discordgo.MessageAllowedMentions{
+				Users: missing,
+			},
+			Reference: msg.Reference(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("channelMessageSendComplex: %v", err)
+		}
+	}
+	ctxzap.Info(ctx, fmt.Sprintf("Reminded %v users to vote", len(missing)))
+	err = g.SetPollReminded(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("setPollReminded: %v", err)
+	}
+	return utils.NewWebhookEdit(fmt.Sprintf("Reminded %v users to vote", len(missing))), nil
+}
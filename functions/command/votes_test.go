@@ -0,0 +1,48 @@
+package command
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMostVoted(t *testing.T) {
+	tests := []struct {
+		name       string
+		voters     map[string][]string
+		candidates []string
+		want       []string
+	}{
+		{
+			name:       "single winner",
+			voters:     map[string][]string{"1": {"A"}, "2": {"A", "B"}},
+			candidates: []string{"A", "B"},
+			want:       []string{"A"},
+		},
+		{
+			name:       "tie",
+			voters:     map[string][]string{"1": {"A"}, "2": {"B"}},
+			candidates: []string{"A", "B", "C"},
+			want:       []string{"A", "B"},
+		},
+		{
+			name:       "no votes ties everyone",
+			voters:     map[string][]string{},
+			candidates: []string{"A", "B"},
+			want:       []string{"A", "B"},
+		},
+		{
+			name:       "votes for non candidates are ignored",
+			voters:     map[string][]string{"1": {"Gone"}, "2": {"Gone"}, "3": {"B"}},
+			candidates: []string{"A", "B"},
+			want:       []string{"B"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := mostVoted(tallyVotes(test.voters), test.candidates)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("mostVoted() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
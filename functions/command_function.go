@@ -57,7 +57,7 @@ func CommandPubSub(ctx context.Context, m PubSubMessage) error {
 
 	var cmd command.Command
 	switch discordCmd.Type() {
-	case discordgo.InteractionApplicationCommand, discordgo.InteractionMessageComponent:
+	case discordgo.InteractionApplicationCommand, discordgo.InteractionMessageComponent, discordgo.InteractionModalSubmit:
 		cmd, err = discordCmd.ToCommand(ctx, setup.ClientLoader)
 		if err != nil {
 			return fmt.Errorf("converting to command: %v", err)
@@ -18,12 +18,27 @@ const TTL time.Duration = time.Hour * 24
 type Filter struct {
 	Name string
 	Type string
+	Tag  string
+	Sort string
+}
+
+// Results are kept with the custom ID so every page shows the same list without recomputing it
+type Results struct {
+	Heading string   `firestore:"heading"`
+	Entries []Result `firestore:"entries"`
+}
+
+type Result struct {
+	Name     string `firestore:"name"`
+	Value    string `firestore:"value"`
+	ImageURL string `firestore:"image_url"`
 }
 
 type innerCustomID struct {
 	Timestamp time.Time `firestore:"timestamp"`
 	Type      string    `firestore:"type"`
 	Filter    Filter    `firestore:"filter"`
+	Results   *Results  `firestore:"results,omitempty"`
 }
 
 type CustomID struct {
@@ -48,17 +63,29 @@ func getCollection(cl *clients.Clients) (*firestore.CollectionRef, error) {
 }
 
 func CreateCustomID(ctx context.Context, typ string, filter Filter, page int, cl *clients.Clients) (*CustomID, error) {
+	return createCustomID(ctx, innerCustomID{
+		Timestamp: time.Now().Add(TTL),
+		Type:      typ,
+		Filter:    filter,
+	}, page, cl)
+}
+
+// CreateResultsCustomID creates a custom ID that keeps the results to page through
+func CreateResultsCustomID(ctx context.Context, typ string, results *Results, page int, cl *clients.Clients) (*CustomID, error) {
+	return createCustomID(ctx, innerCustomID{
+		Timestamp: time.Now().Add(TTL),
+		Type:      typ,
+		Results:   results,
+	}, page, cl)
+}
+
+func createCustomID(ctx context.Context, inCID innerCustomID, page int, cl *clients.Clients) (*CustomID, error) {
 	stateCollection, err := getCollection(cl)
 	if err != nil {
 		return nil, fmt.Errorf("getCollection: %v", err)
 	}
 	docName := uuid.New().String()
 	stateDoc := stateCollection.Doc(docName)
-	inCID := innerCustomID{
-		Timestamp: time.Now().Add(TTL),
-		Type:      typ,
-		Filter:    filter,
-	}
 	ctxzap.Info(ctx, fmt.Sprintf("Creating %v in state collection", docName))
 	_, err = stateDoc.Create(ctx, &inCID)
 	if err != nil {
@@ -129,3 +156,8 @@ func (c *CustomID) Type() string {
 func (c *CustomID) Filter() Filter {
 	return c.innerCustomID.Filter
 }
+
+// Results returns the results kept with the custom ID or nil if there are none
+func (c *CustomID) Results() *Results {
+	return c.innerCustomID.Results
+}
@@ -82,7 +82,25 @@ func DiscordFunctionEntry(w http.ResponseWriter, r *http.Request) {
 	switch cmd.Type() {
 	case discordgo.InteractionPing:
 		handlePing(ctx, w)
-	case discordgo.InteractionApplicationCommand, discordgo.InteractionMessageComponent:
+	case discordgo.InteractionApplicationCommand, discordgo.InteractionMessageComponent, discordgo.InteractionModalSubmit:
+		// Modals must be the first response so they can't be deferred
+		modal, err := command.ModalResponse(ctx, &cmd, setup.ClientLoader)
+		if err != nil {
+			slogger.Errorw("Failed to build modal",
+				"error", err,
+			)
+			http.Error(w, "500 Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if modal != nil {
+			err = writeResponse(w, modal)
+			if err != nil {
+				slogger.Errorw("Failed to return modal response",
+					"error", err,
+				)
+			}
+			return
+		}
 		err = forwardCommand(ctx, &cmd)
 		if err != nil {
 			slogger.Errorw("Failed to forward command",
@@ -91,9 +109,10 @@ func DiscordFunctionEntry(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slogger.Info("Deferring response...")
-		if cmd.Type() == discordgo.InteractionApplicationCommand {
+		// Modals opened by a slash command have no message to update
+		if cmd.Type() == discordgo.InteractionApplicationCommand || (cmd.Type() == discordgo.InteractionModalSubmit && cmd.Interaction().Message == nil) {
 			err = writeDeferredResponse(w, discordgo.InteractionResponseDeferredChannelMessageWithSource)
-		} else if cmd.Type() == discordgo.InteractionMessageComponent {
+		} else if cmd.Type() == discordgo.InteractionMessageComponent || cmd.Type() == discordgo.InteractionModalSubmit {
 			err = writeDeferredResponse(w, discordgo.InteractionResponseDeferredMessageUpdate)
 		}
 		if err != nil {
@@ -105,10 +124,10 @@ func DiscordFunctionEntry(w http.ResponseWriter, r *http.Request) {
 	case discordgo.InteractionApplicationCommandAutocomplete:
 		autocompleteResults := []*discordgo.ApplicationCommandOptionChoice{}
 		switch cmd.CommandName() {
-		case "remove", "pool", "force-remove", "override-fow", "nominations":
+		case "remove", "pool", "force-remove", "override-fow", "nominations", "tag", "players", "own", "activity-info", "edit", "rename", "alias":
 			commandData := cmd.Interaction().ApplicationCommandData()
 			cmd_args := utils.OptionsToMap(commandData.Options)
-			if cmd.CommandName() == "nominations" {
+			if cmd.CommandName() == "nominations" || cmd.CommandName() == "tag" || cmd.CommandName() == "own" || cmd.CommandName() == "alias" {
 				subcmd := commandData.Options[0]
 				cmd_args = utils.OptionsToMap(subcmd.Options)
 			}
@@ -122,6 +141,16 @@ func DiscordFunctionEntry(w http.ResponseWriter, r *http.Request) {
 					}
 				}
 			}
+			if gameOpt, ok := cmd_args["game"]; ok && gameOpt.Focused {
+				userText := gameOpt.StringValue()
+				if len(userText) >= MIN_AUTOCOMPLETE_CHARS {
+					autocompleteResults, err = setup.ClientLoader.Rawg().AutocompleteGames(ctx, cmd.Interaction().GuildID, userText, utils.MAX_AUTOCOMPLETE_ENTRIES)
+					if err != nil {
+						ctxzap.Error(ctx, fmt.Sprintf("AutocompleteGames: %v", err))
+						break
+					}
+				}
+			}
 		case "add":
 			commandData := cmd.Interaction().ApplicationCommandData()
 			cmd_args := utils.OptionsToMap(commandData.Options)
@@ -170,10 +199,14 @@ func handlePing(ctx context.Context, w http.ResponseWriter) {
 }
 
 func forwardCommand(ctx context.Context, command *command.DiscordCommand) error {
-	result := setup.CommandTopic.Publish(ctx, &pubsub.Message{
+	commandTopic, err := setup.CommandTopic()
+	if err != nil {
+		return fmt.Errorf("commandTopic: %v", err)
+	}
+	result := commandTopic.Publish(ctx, &pubsub.Message{
 		Data: command.RawInteraction(),
 	})
-	_, err := result.Get(ctx)
+	_, err = result.Get(ctx)
 	if err != nil {
 		return fmt.Errorf("Pubsub.Publish: %v", err)
 	}
@@ -234,6 +267,16 @@ func writeDeferredResponse(w http.ResponseWriter, typ discordgo.InteractionRespo
 	return nil
 }
 
+func writeResponse(w http.ResponseWriter, response *discordgo.InteractionResponse) error {
+	// MUST SET HEADER BEFORE CONTENT
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		return fmt.Errorf("jsonEncoder: %v", err)
+	}
+	return nil
+}
+
 func writeAutocompleteResults(w http.ResponseWriter, results []*discordgo.ApplicationCommandOptionChoice) error {
 	response := discordgo.InteractionResponse{
 		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
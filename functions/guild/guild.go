@@ -10,12 +10,100 @@ import (
 	"github.com/PinkNoize/flavor-of-the-week/functions/setup"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type PollInfo struct {
 	ChannelID   string `firestore:"channel_id"`
 	MessageID   string `firestore:"message_id"`
 	SuddenDeath bool   `firestore:"sudden_death"`
+	// Poll answer ID to activity document ID
+	Answers map[string]string `firestore:"answers"`
+	// Set when the poll is an extension of a poll that missed quorum
+	Extended bool `firestore:"extended"`
+	// Set once non-voters have been reminded to vote
+	Reminded bool `firestore:"reminded"`
+	// Ranked and hidden polls are an embed with components instead of a native poll
+	Ranked   bool        `firestore:"ranked"`
+	Hidden   bool        `firestore:"hidden"`
+	Entries  []PollEntry `firestore:"entries"`
+	ClosesAt *time.Time  `firestore:"closes_at"`
+	// User ID to the entries they voted for, best first
+	Ballots map[string][]string `firestore:"ballots"`
+	// Set when the poll is a match of a tournament
+	Bracket bool `firestore:"bracket"`
+	// Poll for when to play the flavor of the week
+	TimePoll bool `firestore:"time_poll"`
+	// User ID to the entries they voted for in the native poll this one extends
+	CarriedVotes map[string][]string `firestore:"carried_votes"`
+}
+
+type OwnershipInfo struct {
+	MinOwners int `firestore:"min_owners"`
+	// Games owned by fewer members are left out instead of only being picked last
+	Required bool `firestore:"required"`
+}
+
+type EarlyCloseInfo struct {
+	// Number of eligible voters. Takes priority over the role
+	Voters int `firestore:"voters"`
+	// Members of the role are the eligible voters. All members are eligible when unset
+	RoleID string `firestore:"role_id"`
+}
+
+type BracketInfo struct {
+	Round int `firestore:"round"`
+	// Activities still in the tournament. Each pair plays a match
+	Remaining []string `firestore:"remaining"`
+	// Winners of the current round's matches
+	Advancing []string `firestore:"advancing"`
+	// Index of the current match in the round
+	Match int `firestore:"match"`
+	// Seed of each activity where 1 is the top seed
+	Seeds map[string]int `firestore:"seeds"`
+}
+
+// IsComponentPoll returns whether votes are collected by the bot instead of a native poll
+func (p *PollInfo) IsComponentPoll() bool {
+	return p.Ranked || p.Hidden
+}
+
+const (
+	VOTING_NATIVE = "native"
+	VOTING_RANKED = "ranked"
+	VOTING_HIDDEN = "hidden"
+)
+
+type QuorumAction string
+
+const (
+	QUORUM_EXTEND   QuorumAction = "extend"
+	QUORUM_NEW_POLL QuorumAction = "new-poll"
+	QUORUM_KEEP_FOW QuorumAction = "keep-fow"
+)
+
+type QuorumInfo struct {
+	MinVotes    int          `firestore:"min_votes"`
+	RoleID      string       `firestore:"role_id"`
+	RolePercent int          `firestore:"role_percent"`
+	Action      QuorumAction `firestore:"action"`
+}
+
+type PollEntry struct {
+	Name   string `firestore:"name"`
+	Emoji  string `firestore:"emoji"`
+	Source string `firestore:"source"`
+	Locked bool   `firestore:"locked"`
+}
+
+type PollPreview struct {
+	Entries []PollEntry `firestore:"entries"`
+}
+
+type CompositionSlot struct {
+	Provider string `firestore:"provider"`
+	Weight   int    `firestore:"weight"`
 }
 
 type ScheduleInfo struct {
@@ -23,12 +111,64 @@ type ScheduleInfo struct {
 	Hour int          `firestore:"hour"`
 }
 
+type TimeSlot struct {
+	Day    time.Weekday `firestore:"day"`
+	Hour   int          `firestore:"hour"`
+	Minute int          `firestore:"minute"`
+}
+
+func (t TimeSlot) String() string {
+	return fmt.Sprintf("%v %02d:%02d UTC", t.Day, t.Hour, t.Minute)
+}
+
+// Firestore does not support nested arrays so each team is wrapped
+type Team struct {
+	Members []string `firestore:"members"`
+}
+
+type SessionInfo struct {
+	Day       time.Weekday `firestore:"day"`
+	Hour      int          `firestore:"hour"`
+	Minute    int          `firestore:"minute"`
+	ChannelID string       `firestore:"channel_id"`
+	// Length of the session in hours
+	Duration int `firestore:"duration"`
+}
+
+func (s *SessionInfo) Slot() TimeSlot {
+	return TimeSlot{
+		Day:    s.Day,
+		Hour:   s.Hour,
+		Minute: s.Minute,
+	}
+}
+
 type innerGuild struct {
-	PollChannelID *string       `firestore:"poll_channel_id"`
-	ActivePoll    *PollInfo     `firestore:"active_poll"`
-	Fow           *string       `firestore:"fow"`
-	FowCount      int           `firestore:"fow_count"`
-	Schedule      *ScheduleInfo `firestore:"schedule"`
+	PollChannelID   *string           `firestore:"poll_channel_id"`
+	ActivePoll      *PollInfo         `firestore:"active_poll"`
+	Fow             *string           `firestore:"fow"`
+	FowCount        int               `firestore:"fow_count"`
+	Schedule        *ScheduleInfo     `firestore:"schedule"`
+	PollPreview     *PollPreview      `firestore:"poll_preview"`
+	Composition     []CompositionSlot `firestore:"poll_composition"`
+	FairNominations bool              `firestore:"fair_nominations"`
+	LeftOut         map[string]int    `firestore:"left_out"`
+	Quorum          *QuorumInfo       `firestore:"quorum"`
+	// Users that don't want their votes recorded
+	VoteTrackingOptOut map[string]bool `firestore:"vote_tracking_opt_out"`
+	VotingMode         string          `firestore:"voting_mode"`
+	Bracket            *BracketInfo    `firestore:"bracket"`
+	EarlyClose         *EarlyCloseInfo `firestore:"early_close"`
+	// Number of players expected for the next poll. 0 means any number
+	ExpectedPlayers int            `firestore:"expected_players"`
+	Ownership       *OwnershipInfo `firestore:"ownership"`
+	Session         *SessionInfo   `firestore:"session"`
+	// The scheduled event created for the current flavor of the week
+	SessionEventID string `firestore:"session_event_id"`
+	// Candidate slots for the session time poll. The poll is skipped when there are less than 2
+	TimeSlots []TimeSlot `firestore:"time_slots"`
+	// Teams last picked by /teams, used to avoid repeating pairings
+	LastTeams []Team `firestore:"last_teams"`
 }
 
 type Guild struct {
@@ -120,6 +260,42 @@ func (g *Guild) GetFow(ctx context.Context) (*string, error) {
 	return g.inner.Fow, nil
 }
 
+// RenameActivity points the flavor of the week and the poll preview at the new name of an activity
+// as part of the transaction renaming it
+func (g *Guild) RenameActivity(tx *firestore.Transaction, oldName, newName string) error {
+	snap, err := tx.Get(g.docRef)
+	if err != nil {
+		// Guilds that never ran a poll have nothing to update
+		if status.Code(err) == codes.NotFound {
+			return nil
+		}
+		return fmt.Errorf("get: %v", err)
+	}
+	var inner innerGuild
+	err = snap.DataTo(&inner)
+	if err != nil {
+		return fmt.Errorf("DataTo: %v", err)
+	}
+	updates := make(map[string]interface{})
+	if inner.Fow != nil && *inner.Fow == oldName {
+		updates["fow"] = newName
+	}
+	if inner.PollPreview != nil {
+		for i := range inner.PollPreview.Entries {
+			if inner.PollPreview.Entries[i].Name == oldName {
+				inner.PollPreview.Entries[i].Name = newName
+				updates["poll_preview"] = inner.PollPreview
+			}
+		}
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	// Reload on next use as the transaction may be retried or fail
+	g.loaded = false
+	return tx.Set(g.docRef, updates, firestore.MergeAll)
+}
+
 func (g *Guild) GetFowCount(ctx context.Context) (int, error) {
 	err := g.load(ctx)
 	if err != nil {
@@ -136,6 +312,23 @@ func (g *Guild) GetActivePoll(ctx context.Context) (*PollInfo, error) {
 	return g.inner.ActivePoll, nil
 }
 
+// ClearTimePoll ends the session time poll. Expected players are kept for the next poll
+func (g *Guild) ClearTimePoll(ctx context.Context) error {
+	ctxzap.Info(ctx, "Clearing time poll")
+	_, err := g.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "active_poll",
+			Value: firestore.Delete,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	g.inner.ActivePoll = nil
+	return nil
+}
+
+// ClearActivePoll ends the active poll along with the expected players set for it
 func (g *Guild) ClearActivePoll(ctx context.Context) error {
 	ctxzap.Info(ctx, "Clearing active poll")
 	_, err := g.docRef.Update(ctx, []firestore.Update{
@@ -143,18 +336,24 @@ func (g *Guild) ClearActivePoll(ctx context.Context) error {
 			Path:  "active_poll",
 			Value: firestore.Delete,
 		},
+		{
+			Path:  "expected_players",
+			Value: firestore.Delete,
+		},
 	})
 	if err != nil {
 		return err
 	}
 	g.inner.ActivePoll = nil
+	g.inner.ExpectedPlayers = 0
 	return nil
 }
 
 func (g *Guild) SetActivePoll(ctx context.Context, pollInfo *PollInfo) error {
+	// Replace the whole poll so answers and ballots of a previous poll don't carry over
 	_, err := g.docRef.Set(ctx, map[string]interface{}{
 		"active_poll": pollInfo,
-	}, firestore.MergeAll)
+	}, firestore.Merge([]string{"active_poll"}))
 	if err != nil {
 		return err
 	}
@@ -162,6 +361,425 @@ func (g *Guild) SetActivePoll(ctx context.Context, pollInfo *PollInfo) error {
 	return nil
 }
 
+func (g *Guild) SetPollReminded(ctx context.Context) error {
+	_, err := g.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "active_poll.reminded",
+			Value: true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if g.inner.ActivePoll != nil {
+		g.inner.ActivePoll.Reminded = true
+	}
+	return nil
+}
+
+// ExtendActivePoll moves the closing time of a ranked or hidden poll, keeping its ballots
+func (g *Guild) ExtendActivePoll(ctx context.Context, closesAt time.Time) error {
+	_, err := g.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "active_poll.closes_at",
+			Value: closesAt,
+		},
+		{
+			Path:  "active_poll.extended",
+			Value: true,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if g.inner.ActivePoll != nil {
+		g.inner.ActivePoll.ClosesAt = &closesAt
+		g.inner.ActivePoll.Extended = true
+	}
+	return nil
+}
+
+func (g *Guild) SetBallot(ctx context.Context, userID string, ballot []string) error {
+	_, err := g.docRef.Update(ctx, []firestore.Update{
+		{
+			FieldPath: firestore.FieldPath{"active_poll", "ballots", userID},
+			Value:     ballot,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if g.inner.ActivePoll != nil {
+		if g.inner.ActivePoll.Ballots == nil {
+			g.inner.ActivePoll.Ballots = make(map[string][]string)
+		}
+		g.inner.ActivePoll.Ballots[userID] = ballot
+	}
+	return nil
+}
+
+func (g *Guild) GetPollPreview(ctx context.Context) (*PollPreview, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.PollPreview, nil
+}
+
+func (g *Guild) SetPollPreview(ctx context.Context, preview *PollPreview) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"poll_preview": preview,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.PollPreview = preview
+	return nil
+}
+
+func (g *Guild) ClearPollPreview(ctx context.Context) error {
+	_, err := g.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "poll_preview",
+			Value: firestore.Delete,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	g.inner.PollPreview = nil
+	return nil
+}
+
+func (g *Guild) SetPollComposition(ctx context.Context, composition []CompositionSlot) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"poll_composition": composition,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.Composition = composition
+	return nil
+}
+
+func (g *Guild) GetPollComposition(ctx context.Context) ([]CompositionSlot, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.Composition, nil
+}
+
+func (g *Guild) SetFairNominations(ctx context.Context, enabled bool) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"fair_nominations": enabled,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.FairNominations = enabled
+	return nil
+}
+
+func (g *Guild) GetFairNominations(ctx context.Context) (bool, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	return g.inner.FairNominations, nil
+}
+
+// GetLeftOut returns the number of consecutive polls each user's nominations were left out of
+func (g *Guild) GetLeftOut(ctx context.Context) (map[string]int, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.LeftOut, nil
+}
+
+func (g *Guild) SetLeftOut(ctx context.Context, leftOut map[string]int) error {
+	_, err := g.docRef.Update(ctx, []firestore.Update{
+		{
+			Path:  "left_out",
+			Value: leftOut,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	g.inner.LeftOut = leftOut
+	return nil
+}
+
+func (g *Guild) SetQuorum(ctx context.Context, quorum *QuorumInfo) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"quorum": quorum,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.Quorum = quorum
+	return nil
+}
+
+func (g *Guild) ClearQuorum(ctx context.Context) error {
+	// Merge so clearing works before the guild document exists
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"quorum": firestore.Delete,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.Quorum = nil
+	return nil
+}
+
+func (g *Guild) GetQuorum(ctx context.Context) (*QuorumInfo, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.Quorum, nil
+}
+
+func (g *Guild) SetVoteTracking(ctx context.Context, userID string, enabled bool) error {
+	var value interface{} = true
+	if enabled {
+		value = firestore.Delete
+	}
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"vote_tracking_opt_out": map[string]interface{}{
+			userID: value,
+		},
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	if g.inner.VoteTrackingOptOut == nil {
+		g.inner.VoteTrackingOptOut = make(map[string]bool)
+	}
+	if enabled {
+		delete(g.inner.VoteTrackingOptOut, userID)
+	} else {
+		g.inner.VoteTrackingOptOut[userID] = true
+	}
+	return nil
+}
+
+func (g *Guild) GetVoteTrackingOptOut(ctx context.Context) (map[string]bool, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.VoteTrackingOptOut, nil
+}
+
+func (g *Guild) SetVotingMode(ctx context.Context, mode string) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"voting_mode": mode,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.VotingMode = mode
+	return nil
+}
+
+func (g *Guild) GetVotingMode(ctx context.Context) (string, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	if g.inner.VotingMode == "" {
+		return VOTING_NATIVE, nil
+	}
+	return g.inner.VotingMode, nil
+}
+
+func (g *Guild) SetBracket(ctx context.Context, bracket *BracketInfo) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"bracket": bracket,
+	}, firestore.Merge([]string{"bracket"}))
+	if err != nil {
+		return err
+	}
+	g.inner.Bracket = bracket
+	return nil
+}
+
+func (g *Guild) ClearBracket(ctx context.Context) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"bracket": firestore.Delete,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.Bracket = nil
+	return nil
+}
+
+func (g *Guild) GetBracket(ctx context.Context) (*BracketInfo, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.Bracket, nil
+}
+
+func (g *Guild) SetEarlyClose(ctx context.Context, earlyClose *EarlyCloseInfo) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"early_close": earlyClose,
+	}, firestore.Merge([]string{"early_close"}))
+	if err != nil {
+		return err
+	}
+	g.inner.EarlyClose = earlyClose
+	return nil
+}
+
+func (g *Guild) ClearEarlyClose(ctx context.Context) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"early_close": firestore.Delete,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.EarlyClose = nil
+	return nil
+}
+
+func (g *Guild) GetEarlyClose(ctx context.Context) (*EarlyCloseInfo, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.EarlyClose, nil
+}
+
+func (g *Guild) SetOwnership(ctx context.Context, ownership *OwnershipInfo) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"ownership": ownership,
+	}, firestore.Merge([]string{"ownership"}))
+	if err != nil {
+		return err
+	}
+	g.inner.Ownership = ownership
+	return nil
+}
+
+func (g *Guild) ClearOwnership(ctx context.Context) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"ownership": firestore.Delete,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.Ownership = nil
+	return nil
+}
+
+func (g *Guild) GetOwnership(ctx context.Context) (*OwnershipInfo, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.Ownership, nil
+}
+
+func (g *Guild) SetSession(ctx context.Context, session *SessionInfo) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"session": session,
+	}, firestore.Merge([]string{"session"}))
+	if err != nil {
+		return err
+	}
+	g.inner.Session = session
+	return nil
+}
+
+func (g *Guild) ClearSession(ctx context.Context) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"session":          firestore.Delete,
+		"session_event_id": firestore.Delete,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.Session = nil
+	g.inner.SessionEventID = ""
+	return nil
+}
+
+func (g *Guild) GetSession(ctx context.Context) (*SessionInfo, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.Session, nil
+}
+
+func (g *Guild) SetSessionEvent(ctx context.Context, eventID string) error {
+	var value interface{} = eventID
+	if eventID == "" {
+		value = firestore.Delete
+	}
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"session_event_id": value,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.SessionEventID = eventID
+	return nil
+}
+
+func (g *Guild) GetSessionEvent(ctx context.Context) (string, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	return g.inner.SessionEventID, nil
+}
+
+func (g *Guild) SetTimeSlots(ctx context.Context, slots []TimeSlot) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"time_slots": slots,
+	}, firestore.Merge([]string{"time_slots"}))
+	if err != nil {
+		return err
+	}
+	g.inner.TimeSlots = slots
+	return nil
+}
+
+func (g *Guild) GetTimeSlots(ctx context.Context) ([]TimeSlot, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.TimeSlots, nil
+}
+
+func (g *Guild) SetLastTeams(ctx context.Context, teams []Team) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"last_teams": teams,
+	}, firestore.Merge([]string{"last_teams"}))
+	if err != nil {
+		return err
+	}
+	g.inner.LastTeams = teams
+	return nil
+}
+
+func (g *Guild) GetLastTeams(ctx context.Context) ([]Team, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.inner.LastTeams, nil
+}
+
 func GetGuildsWithActivePolls(ctx context.Context, cl *clients.Clients) ([]*Guild, error) {
 	guildCollection, err := getCollection(cl)
 	if err != nil {
@@ -260,3 +878,22 @@ func GetGuildsWithSchedule(ctx context.Context, day time.Weekday, hour int, cl *
 	}
 	return results, nil
 }
+
+func (g *Guild) SetExpectedPlayers(ctx context.Context, players int) error {
+	_, err := g.docRef.Set(ctx, map[string]interface{}{
+		"expected_players": players,
+	}, firestore.MergeAll)
+	if err != nil {
+		return err
+	}
+	g.inner.ExpectedPlayers = players
+	return nil
+}
+
+func (g *Guild) GetExpectedPlayers(ctx context.Context) (int, error) {
+	err := g.load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return g.inner.ExpectedPlayers, nil
+}
@@ -0,0 +1,252 @@
+package history
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/setup"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	RSVP_GOING = "going"
+	RSVP_MAYBE = "maybe"
+	RSVP_NO    = "no"
+)
+
+type FowRecord struct {
+	GuildID   string    `firestore:"guild_id"`
+	Activity  string    `firestore:"activity"`
+	StartedAt time.Time `firestore:"started_at"`
+	// The RSVP message posted for the flavor of the week
+	ChannelID string `firestore:"channel_id"`
+	MessageID string `firestore:"message_id"`
+	// User ID to their RSVP response
+	Rsvps map[string]string `firestore:"rsvps"`
+	// Slot picked by the session time poll
+	SessionSlot  string     `firestore:"session_slot"`
+	SessionStart *time.Time `firestore:"session_start"`
+	// The message asking members to rate the flavor of the week once it is over
+	RatingMessageID string `firestore:"rating_message_id"`
+}
+
+// Attendees returns the users that gave the response
+func (r *FowRecord) Attendees(response string) []string {
+	users := make([]string, 0)
+	for user, rsvp := range r.Rsvps {
+		if rsvp == response {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+func getCollection(cl *clients.Clients) (*firestore.CollectionRef, error) {
+	firestoreClient, err := cl.Firestore()
+	if err != nil {
+		return nil, err
+	}
+	return firestoreClient.Collection(fmt.Sprintf("flavor-of-the-week-history-%v", setup.ENV)), nil
+}
+
+func generateName(guildID, messageID string) string {
+	return fmt.Sprintf("%v:%v", guildID, messageID)
+}
+
+func RecordFow(ctx context.Context, record *FowRecord, cl *clients.Clients) error {
+	historyCollection, err := getCollection(cl)
+	if err != nil {
+		return fmt.Errorf("getCollection: %v", err)
+	}
+	_, err = historyCollection.Doc(generateName(record.GuildID, record.MessageID)).Set(ctx, record)
+	if err != nil {
+		return fmt.Errorf("set: %v", err)
+	}
+	return nil
+}
+
+// SetSession stores the session time picked for the flavor of the week
+func SetSession(ctx context.Context, record *FowRecord, slot string, start time.Time, cl *clients.Clients) error {
+	historyCollection, err := getCollection(cl)
+	if err != nil {
+		return fmt.Errorf("getCollection: %v", err)
+	}
+	_, err = historyCollection.Doc(generateName(record.GuildID, record.MessageID)).Update(ctx, []firestore.Update{
+		{
+			Path:  "session_slot",
+			Value: slot,
+		},
+		{
+			Path:  "session_start",
+			Value: start,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("update: %v", err)
+	}
+	record.SessionSlot = slot
+	record.SessionStart = &start
+	return nil
+}
+
+func SetRatingMessage(ctx context.Context, record *FowRecord, messageID string, cl *clients.Clients) error {
+	historyCollection, err := getCollection(cl)
+	if err != nil {
+		return fmt.Errorf("getCollection: %v", err)
+	}
+	_, err = historyCollection.Doc(generateName(record.GuildID, record.MessageID)).Update(ctx, []firestore.Update{
+		{
+			Path:  "rating_message_id",
+			Value: messageID,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("update: %v", err)
+	}
+	record.RatingMessageID = messageID
+	return nil
+}
+
+// GetFowByRatingMessage returns the flavor of the week the rating message was posted for. Returns nil if there is none
+func GetFowByRatingMessage(ctx context.Context, guildID, messageID string, cl *clients.Clients) (*FowRecord, error) {
+	historyCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	query := historyCollection.WhereEntity(firestore.AndFilter{
+		Filters: []firestore.EntityFilter{
+			firestore.PropertyFilter{
+				Path:     "guild_id",
+				Operator: "==",
+				Value:    guildID,
+			},
+			firestore.PropertyFilter{
+				Path:     "rating_message_id",
+				Operator: "==",
+				Value:    messageID,
+			},
+		},
+	}).Limit(1)
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("getAll: %v", err)
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	var record FowRecord
+	err = docs[0].DataTo(&record)
+	if err != nil {
+		return nil, fmt.Errorf("doc.DataTo: %v", err)
+	}
+	return &record, nil
+}
+
+// SetRsvp stores a user's response to the RSVP message and returns the updated record
+func SetRsvp(ctx context.Context, guildID, messageID, userID, response string, cl *clients.Clients) (*FowRecord, error) {
+	historyCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	doc := historyCollection.Doc(generateName(guildID, messageID))
+	_, err = doc.Update(ctx, []firestore.Update{
+		{
+			FieldPath: firestore.FieldPath{"rsvps", userID},
+			Value:     response,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	snap, err := doc.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get: %v", err)
+	}
+	var record FowRecord
+	err = snap.DataTo(&record)
+	if err != nil {
+		return nil, fmt.Errorf("doc.DataTo: %v", err)
+	}
+	return &record, nil
+}
+
+// RenameActivity updates the flavors of the week recorded under the old name of an activity
+func RenameActivity(ctx context.Context, guildID, oldName, newName string, cl *clients.Clients) error {
+	historyCollection, err := getCollection(cl)
+	if err != nil {
+		return fmt.Errorf("getCollection: %v", err)
+	}
+	query := historyCollection.WhereEntity(firestore.AndFilter{
+		Filters: []firestore.EntityFilter{
+			firestore.PropertyFilter{
+				Path:     "guild_id",
+				Operator: "==",
+				Value:    guildID,
+			},
+			firestore.PropertyFilter{
+				Path:     "activity",
+				Operator: "==",
+				Value:    oldName,
+			},
+		},
+	})
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("iter.Next: %v", err)
+		}
+		_, err = doc.Ref.Update(ctx, []firestore.Update{
+			{
+				Path:  "activity",
+				Value: newName,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("update: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetRecentFows returns the last n flavors of the week of the guild, newest first
+func GetRecentFows(ctx context.Context, guildID string, n int, cl *clients.Clients) ([]*FowRecord, error) {
+	historyCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	// This query requires an index which is created in terraform
+	query := historyCollection.WhereEntity(&firestore.PropertyFilter{
+		Path:     "guild_id",
+		Operator: "==",
+		Value:    guildID,
+	}).OrderBy("started_at", firestore.Desc).Limit(n)
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	results := make([]*FowRecord, 0, n)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iter.Next: %v", err)
+		}
+		var record FowRecord
+		err = doc.DataTo(&record)
+		if err != nil {
+			return nil, fmt.Errorf("doc.DataTo: %v", err)
+		}
+		results = append(results, &record)
+	}
+	return results, nil
+}
@@ -35,6 +35,10 @@ func PollPubSub(ctx context.Context, _ PubSubMessage) error {
 	if err != nil {
 		slogger.Errorf("notifyUpcomingPolls: %v", err)
 	}
+	err = remindNonVoters(ctx, setup.ClientLoader)
+	if err != nil {
+		slogger.Errorf("remindNonVoters: %v", err)
+	}
 	err = endActivePolls(ctx, setup.ClientLoader)
 	if err != nil {
 		slogger.Errorf("endActivePolls: %v", err)
@@ -66,16 +70,22 @@ func endActivePolls(ctx context.Context, cl *clients.Clients) error {
 			return fmt.Errorf("GetActivePoll: %v", err)
 		}
 		// Check if its active
-		msg, err := discordClient.ChannelMessage(activePoll.ChannelID, activePoll.MessageID)
-		if err != nil {
-			ctxzap.Warn(ctx, fmt.Sprintf("Failed to get channel message for %v: %v %v", g.GetGuildId(), activePoll.ChannelID, activePoll.MessageID))
-			continue
-		}
-		if msg.Poll == nil {
-			ctxzap.Warn(ctx, fmt.Sprintf("No poll for guild %v", g.GetGuildId()))
-			continue
+		var expiry *time.Time
+		if activePoll.IsComponentPoll() {
+			expiry = activePoll.ClosesAt
+		} else {
+			msg, err := discordClient.ChannelMessage(activePoll.ChannelID, activePoll.MessageID)
+			if err != nil {
+				ctxzap.Warn(ctx, fmt.Sprintf("Failed to get channel message for %v: %v %v", g.GetGuildId(), activePoll.ChannelID, activePoll.MessageID))
+				continue
+			}
+			if msg.Poll == nil {
+				ctxzap.Warn(ctx, fmt.Sprintf("No poll for guild %v", g.GetGuildId()))
+				continue
+			}
+			expiry = msg.Poll.Expiry
 		}
-		if msg.Poll.Expiry != nil && msg.Poll.Expiry.Before(time.Now()) {
+		if expiry != nil && expiry.Before(time.Now()) {
 			ctxzap.Info(ctx, fmt.Sprintf("Poll for %v has ended. Ending poll", g.GetGuildId()))
 			// End it if active
 			cmd := command.NewEndPollCommand(g.GetGuildId())
@@ -86,6 +96,32 @@ func endActivePolls(ctx context.Context, cl *clients.Clients) error {
 			}
 		} else {
 			ctxzap.Info(ctx, fmt.Sprintf("Poll for %v has not ended", g.GetGuildId()))
+			cmd := command.NewCheckEarlyCloseCommand(g.GetGuildId())
+			_, err = cmd.Execute(ctx, cl)
+			if err != nil {
+				ctxzap.Warn(ctx, fmt.Sprintf("CheckEarlyCloseCommand: %v", err))
+				continue
+			}
+		}
+	}
+	return nil
+}
+
+func remindNonVoters(ctx context.Context, cl *clients.Clients) error {
+	guilds, err := guild.GetGuildsWithActivePolls(ctx, cl)
+	if err != nil {
+		return fmt.Errorf("GetGuildsWithActivePolls: %v", err)
+	}
+	prevContext := ctx
+	for _, g := range guilds {
+		ctx = prevContext
+		ctxzap.AddFields(ctx, zap.String("guildID", g.GetGuildId()))
+
+		cmd := command.NewRemindVotersCommand(g.GetGuildId())
+		_, err = cmd.Execute(ctx, cl)
+		if err != nil {
+			ctxzap.Warn(ctx, fmt.Sprintf("RemindVotersCommand: %v", err))
+			continue
 		}
 	}
 	return nil
@@ -58,7 +58,7 @@ func main() {
 		}
 
 		// Copy to new server
-		_, err = activity.Create(ctx, inAct.Typ, inAct.Name, dest_server, inAct.GameInfo, client)
+		_, err = activity.Create(ctx, inAct.Typ, inAct.Name, dest_server, inAct.GameInfo, inAct.Tags, inAct.MinPlayers, inAct.MaxPlayers, inAct.CreatedBy, client)
 		if err != nil {
 			log.Fatalf("activity.Create: %s", err)
 		}
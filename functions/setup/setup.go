@@ -3,11 +3,13 @@ package setup
 import (
 	"context"
 	"encoding/hex"
+	"fmt"
 	"log"
 	"os"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/josestg/lazy"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -20,7 +22,7 @@ var ResourcesBucket string = os.Getenv("RESOURCES_BUCKET")
 
 var DiscordPubkey []byte
 var ClientLoader *clients.Clients
-var CommandTopic *pubsub.Topic
+var commandTopic lazy.Loader[*pubsub.Topic]
 var ZapLogger *zap.Logger
 var ZapSlogger *zap.SugaredLogger
 
@@ -28,11 +30,14 @@ func init() {
 	var err error
 	ctx := context.Background()
 	ZapLogger, ZapSlogger = setup_loggers()
-	pubsubClient, err := pubsub.NewClient(ctx, ProjectID)
-	if err != nil {
-		ZapSlogger.Fatalf("Failed to create pubsub client: %v", err)
-	}
-	CommandTopic = pubsubClient.Topic(CommandTopicID)
+	// Created on first use so packages importing setup don't need pubsub credentials
+	commandTopic = lazy.New(func() (*pubsub.Topic, error) {
+		pubsubClient, err := pubsub.NewClient(ctx, ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create pubsub client: %v", err)
+		}
+		return pubsubClient.Topic(CommandTopicID), nil
+	})
 
 	DiscordPubkey, err = hex.DecodeString(os.Getenv("DISCORD_PUBKEY"))
 	if err != nil {
@@ -41,6 +46,14 @@ func init() {
 	ClientLoader = clients.New(ctx, ProjectID, os.Getenv("DISCORD_TOKEN"), os.Getenv("RAWG_TOKEN"))
 }
 
+func CommandTopic() (*pubsub.Topic, error) {
+	topic := commandTopic.Value()
+	if topic == nil {
+		return nil, commandTopic.Error()
+	}
+	return topic, nil
+}
+
 func setup_loggers() (*zap.Logger, *zap.SugaredLogger) {
 	logger, err := newZapLogger()
 	if err != nil {
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/PinkNoize/flavor-of-the-week/functions/customid"
 	"github.com/bwmarrin/discordgo"
@@ -19,6 +20,11 @@ type GameEntry struct {
 	Name        string
 	Nominations *int
 	ImageURL    string
+	Tags        []string
+	Players     string
+	Owners      *int
+	Rating      string
+	Added       string
 }
 
 // This needs to be refactored with some kind of options factory
@@ -35,6 +41,21 @@ func BuildDiscordPage(gameEntries []GameEntry, customID *customid.CustomID, page
 		if ent.Nominations != nil {
 			description = fmt.Sprintf("Nominations: %v", *ent.Nominations)
 		}
+		if ent.Owners != nil {
+			description = strings.TrimSpace(fmt.Sprintf("%v\nOwned by: %v", description, *ent.Owners))
+		}
+		if ent.Players != "" {
+			description = strings.TrimSpace(fmt.Sprintf("%v\nPlayers: %v", description, ent.Players))
+		}
+		if ent.Rating != "" {
+			description = strings.TrimSpace(fmt.Sprintf("%v\nRating: %v", description, ent.Rating))
+		}
+		if len(ent.Tags) > 0 {
+			description = strings.TrimSpace(fmt.Sprintf("%v\nTags: %v", description, strings.Join(ent.Tags, ", ")))
+		}
+		if ent.Added != "" {
+			description = strings.TrimSpace(fmt.Sprintf("%v\nAdded: %v", description, ent.Added))
+		}
 		embeds = append(embeds, &discordgo.MessageEmbed{
 			Type:        discordgo.EmbedTypeRich,
 			Title:       ent.Name,
@@ -0,0 +1,180 @@
+package votes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/PinkNoize/flavor-of-the-week/functions/clients"
+	"github.com/PinkNoize/flavor-of-the-week/functions/setup"
+	"google.golang.org/api/iterator"
+)
+
+type PollRecord struct {
+	GuildID   string    `firestore:"guild_id"`
+	MessageID string    `firestore:"message_id"`
+	EndedAt   time.Time `firestore:"ended_at"`
+	// Activities with the most votes. Has multiple entries on a tie
+	Winners []string `firestore:"winners"`
+	// Number of users that voted including those who opted out of tracking
+	VoterCount int `firestore:"voter_count"`
+	// User ID to the activities they voted for
+	Voters map[string][]string `firestore:"voters"`
+}
+
+func getCollection(cl *clients.Clients) (*firestore.CollectionRef, error) {
+	firestoreClient, err := cl.Firestore()
+	if err != nil {
+		return nil, err
+	}
+	return firestoreClient.Collection(fmt.Sprintf("flavor-of-the-week-votes-%v", setup.ENV)), nil
+}
+
+func generateName(guildID, messageID string) string {
+	return fmt.Sprintf("%v:%v", guildID, messageID)
+}
+
+func RecordPoll(ctx context.Context, record *PollRecord, cl *clients.Clients) error {
+	votesCollection, err := getCollection(cl)
+	if err != nil {
+		return fmt.Errorf("getCollection: %v", err)
+	}
+	_, err = votesCollection.Doc(generateName(record.GuildID, record.MessageID)).Set(ctx, record)
+	if err != nil {
+		return fmt.Errorf("set: %v", err)
+	}
+	return nil
+}
+
+// GetRecentPolls returns the last n polls of the guild, newest first
+func GetRecentPolls(ctx context.Context, guildID string, n int, cl *clients.Clients) ([]*PollRecord, error) {
+	votesCollection, err := getCollection(cl)
+	if err != nil {
+		return nil, fmt.Errorf("getCollection: %v", err)
+	}
+	// This query requires an index which is created in terraform
+	query := votesCollection.WhereEntity(&firestore.PropertyFilter{
+		Path:     "guild_id",
+		Operator: "==",
+		Value:    guildID,
+	}).OrderBy("ended_at", firestore.Desc).Limit(n)
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	results := make([]*PollRecord, 0, n)
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("iter.Next: %v", err)
+		}
+		var record PollRecord
+		err = doc.DataTo(&record)
+		if err != nil {
+			return nil, fmt.Errorf("doc.DataTo: %v", err)
+		}
+		results = append(results, &record)
+	}
+	return results, nil
+}
+
+// RenameActivity replaces the old name of an activity in the recorded polls of the guild
+func RenameActivity(ctx context.Context, guildID, oldName, newName string, cl *clients.Clients) error {
+	votesCollection, err := getCollection(cl)
+	if err != nil {
+		return fmt.Errorf("getCollection: %v", err)
+	}
+	query := votesCollection.WhereEntity(&firestore.PropertyFilter{
+		Path:     "guild_id",
+		Operator: "==",
+		Value:    guildID,
+	})
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	rename := func(names []string) bool {
+		renamed := false
+		for i, name := range names {
+			if name == oldName {
+				names[i] = newName
+				renamed = true
+			}
+		}
+		return renamed
+	}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("iter.Next: %v", err)
+		}
+		var record PollRecord
+		err = doc.DataTo(&record)
+		if err != nil {
+			return fmt.Errorf("doc.DataTo: %v", err)
+		}
+		updates := make([]firestore.Update, 0, 1)
+		if rename(record.Winners) {
+			updates = append(updates, firestore.Update{
+				Path:  "winners",
+				Value: record.Winners,
+			})
+		}
+		for userID, voted := range record.Voters {
+			if rename(voted) {
+				updates = append(updates, firestore.Update{
+					FieldPath: firestore.FieldPath{"voters", userID},
+					Value:     voted,
+				})
+			}
+		}
+		if len(updates) == 0 {
+			continue
+		}
+		_, err = doc.Ref.Update(ctx, updates)
+		if err != nil {
+			return fmt.Errorf("update: %v", err)
+		}
+	}
+	return nil
+}
+
+// RemoveVoter deletes the recorded votes of a user in the guild
+func RemoveVoter(ctx context.Context, guildID, userID string, cl *clients.Clients) error {
+	votesCollection, err := getCollection(cl)
+	if err != nil {
+		return fmt.Errorf("getCollection: %v", err)
+	}
+	query := votesCollection.WhereEntity(&firestore.PropertyFilter{
+		Path:     "guild_id",
+		Operator: "==",
+		Value:    guildID,
+	})
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("iter.Next: %v", err)
+		}
+		_, err = doc.Ref.Update(ctx, []firestore.Update{
+			{
+				FieldPath: firestore.FieldPath{"voters", userID},
+				Value:     firestore.Delete,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("update: %v", err)
+		}
+	}
+	return nil
+}
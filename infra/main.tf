@@ -192,6 +192,206 @@ resource "google_firestore_index" "random-2-index" {
   }
 }
 
+resource "google_firestore_index" "random-1-type-index" {
+  project    = var.project
+  database   = "(default)"
+  collection = "flavor-of-the-week-${var.env}"
+
+  fields {
+    field_path = "guild_id"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path = "type"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path = "random.num_1"
+    order      = "ASCENDING"
+  }
+}
+
+resource "google_firestore_index" "random-2-type-index" {
+  project    = var.project
+  database   = "(default)"
+  collection = "flavor-of-the-week-${var.env}"
+
+  fields {
+    field_path = "guild_id"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path = "type"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path = "random.num_2"
+    order      = "ASCENDING"
+  }
+}
+
+resource "google_firestore_index" "pool-tag-search-index" {
+  project    = var.project
+  database   = "(default)"
+  collection = "flavor-of-the-week-${var.env}"
+
+  fields {
+    field_path = "guild_id"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path   = "tags"
+    array_config = "CONTAINS"
+  }
+
+  fields {
+    field_path = "search_name"
+    order      = "ASCENDING"
+  }
+}
+
+resource "google_firestore_index" "pool-type-tag-search-index" {
+  project    = var.project
+  database   = "(default)"
+  collection = "flavor-of-the-week-${var.env}"
+
+  fields {
+    field_path = "type"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path = "guild_id"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path   = "tags"
+    array_config = "CONTAINS"
+  }
+
+  fields {
+    field_path = "search_name"
+    order      = "ASCENDING"
+  }
+}
+
+resource "google_firestore_index" "random-1-tag-index" {
+  project    = var.project
+  database   = "(default)"
+  collection = "flavor-of-the-week-${var.env}"
+
+  fields {
+    field_path = "guild_id"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path   = "tags"
+    array_config = "CONTAINS"
+  }
+
+  fields {
+    field_path = "random.num_1"
+    order      = "ASCENDING"
+  }
+}
+
+resource "google_firestore_index" "random-2-tag-index" {
+  project    = var.project
+  database   = "(default)"
+  collection = "flavor-of-the-week-${var.env}"
+
+  fields {
+    field_path = "guild_id"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path   = "tags"
+    array_config = "CONTAINS"
+  }
+
+  fields {
+    field_path = "random.num_2"
+    order      = "ASCENDING"
+  }
+}
+
+resource "google_firestore_index" "owners-search-index" {
+  project    = var.project
+  database   = "(default)"
+  collection = "flavor-of-the-week-${var.env}"
+
+  fields {
+    field_path = "guild_id"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path   = "owners"
+    array_config = "CONTAINS"
+  }
+
+  fields {
+    field_path = "search_name"
+    order      = "ASCENDING"
+  }
+}
+
+resource "google_firestore_index" "newest-index" {
+  project    = var.project
+  database   = "(default)"
+  collection = "flavor-of-the-week-${var.env}"
+
+  fields {
+    field_path = "guild_id"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path = "created_at"
+    order      = "DESCENDING"
+  }
+}
+
+resource "google_firestore_index" "votes-history-index" {
+  project    = var.project
+  database   = "(default)"
+  collection = "flavor-of-the-week-votes-${var.env}"
+
+  fields {
+    field_path = "guild_id"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path = "ended_at"
+    order      = "DESCENDING"
+  }
+}
+
+resource "google_firestore_index" "fow-history-index" {
+  project    = var.project
+  database   = "(default)"
+  collection = "flavor-of-the-week-history-${var.env}"
+
+  fields {
+    field_path = "guild_id"
+    order      = "ASCENDING"
+  }
+
+  fields {
+    field_path = "started_at"
+    order      = "DESCENDING"
+  }
+}
+
 resource "google_firestore_field" "state-ttl-delete" {
   project    = var.project
   database   = "(default)"